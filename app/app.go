@@ -8,49 +8,137 @@ import (
 	"runtime/debug"
 	"syscall"
 
-	"github.com/honeycombio/otel-config-go/otelconfig"
 	"golang.org/x/sync/errgroup"
-	"maragu.dev/env"
 	"maragu.dev/errors"
 
+	"maragu.dev/glue/config"
+	"maragu.dev/glue/health"
 	"maragu.dev/glue/log"
 )
 
-// Goer is just the executing part of [errgroup.Group].
+// Goer is the executing part of [errgroup.Group], plus adding [Component]s to be started and stopped in dependency order.
 type Goer interface {
 	Go(func() error)
+	Add(c Component)
 }
 
-// StartFunc is given to [Start] and should not block, instead starting components with the given error group.
+// StartFunc is given to [Start] and should not block, instead starting components with the given error group,
+// or adding them with [Goer.Add].
 type StartFunc = func(ctx context.Context, log *slog.Logger, eg Goer) error
 
+// StartOption for [Start].
+type StartOption func(*startConfig)
+
+// startConfig used with [StartOption].
+type startConfig struct {
+	configs          []any
+	exporter         Exporter
+	exporterEndpoint string
+	exporterFilePath string
+	health           *health.Registry
+	logLevel         *slog.LevelVar
+	metrics          bool
+	onReload         []func(ctx context.Context) error
+	onStarted        func()
+}
+
+// WithConfig loads the config struct pointed to by v with [config.Load] before the app starts,
+// and logs the effective configuration with secrets redacted.
+// All config errors are reported together, and stop the app from starting.
+// If v is a [config.Reloadable], it's also loaded again on SIGHUP, see [WithOnReload].
+func WithConfig(v any) StartOption {
+	return func(c *startConfig) {
+		c.configs = append(c.configs, v)
+	}
+}
+
+// WithHealth makes readiness checks in the given [health.Registry] fail as soon as the app starts shutting down.
+func WithHealth(r *health.Registry) StartOption {
+	return func(c *startConfig) {
+		c.health = r
+	}
+}
+
+// WithOnStarted calls f once the start callback has returned and all components have been started.
+func WithOnStarted(f func()) StartOption {
+	return func(c *startConfig) {
+		c.onStarted = f
+	}
+}
+
+// WithLogLevel uses the given level for the app logger, for example to share it with [maragu.dev/glue/http.NewServerOptions].
+// It's set from LOG_LEVEL at startup, and again on SIGHUP, see [WithOnReload].
+func WithLogLevel(v *slog.LevelVar) StartOption {
+	return func(c *startConfig) {
+		c.logLevel = v
+	}
+}
+
+// WithOnReload calls f when the app receives SIGHUP, after LOG_LEVEL and all [config.Reloadable] configs
+// given with [WithConfig] have been loaded again, and before [Component] Reload funcs are called.
+// Errors are logged, and don't stop the app.
+func WithOnReload(f func(ctx context.Context) error) StartOption {
+	return func(c *startConfig) {
+		c.onReload = append(c.onReload, f)
+	}
+}
+
 // Start sets up the main application context, the [slog.Logger], an [errgroup.Group], and OpenTelemetry tracing, and calls the given callback.
 // The callback function should start up all necessary components of the app using the error group, and not block on anything itself in the main goroutine.
-func Start(startCallback StartFunc) {
+// Tracing exports to Honeycomb by default, see [WithExporter] for alternatives.
+// See [Commands] for running the app in different roles from the same binary.
+func Start(startCallback StartFunc, opts ...StartOption) {
+	main(func(ctx context.Context, log *slog.Logger, name string, opts ...StartOption) error {
+		return Run(ctx, log, name, startCallback, opts...)
+	}, opts...)
+}
+
+// main sets up signal handling, app config, and the logger, and calls run with the options, exiting on errors.
+func main(run func(ctx context.Context, log *slog.Logger, name string, opts ...StartOption) error, opts ...StartOption) {
 	// Catch SIGTERM and SIGINT from the terminal, so we can do clean shutdowns.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
 	// This loads from .env in development and /run/secrets/env when run in a container through Docker compose.
-	// We ignore errors because neither may exist.
-	_ = env.Load(".env")
-	_ = env.Load("/run/secrets/env")
+	// Neither may exist, which is fine.
+	var appConfig config.App
+	configErr := config.Load(&appConfig, envPaths...)
+	if configErr != nil {
+		// Fall back to defaults, so we can at least log the error
+		appConfig = config.App{Name: "App", LogJSON: true, LogLevel: "info"}
+	}
 
 	// Create a new default log that is injected into all parts of the application.
 	// It uses the slog package from stdlib, which is a leveled, structured logger.
-	// We can output plain text or JSON as needed.
+	// We can output plain text or JSON as needed, and the level can be changed on SIGHUP.
+	var c startConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.logLevel == nil {
+		c.logLevel = new(slog.LevelVar)
+		opts = append(opts, WithLogLevel(c.logLevel))
+	}
+	c.logLevel.Set(log.StringToLevel(appConfig.LogLevel))
+
 	log := log.NewLogger(log.NewLoggerOptions{
-		JSON:   env.GetBoolOrDefault("LOG_JSON", true),
-		Level:  log.StringToLevel(env.GetStringOrDefault("LOG_LEVEL", "info")),
-		NoTime: env.GetBoolOrDefault("LOG_NO_TIME", false),
+		JSON:     appConfig.LogJSON,
+		LevelVar: c.logLevel,
+		NoTime:   appConfig.LogNoTime,
 	})
 
-	name := env.GetStringOrDefault("APP_NAME", "App")
-	log.InfoContext(ctx, "Starting app", "name", name)
+	name := appConfig.Name
+
+	if configErr != nil {
+		log.ErrorContext(ctx, "Error loading config", "name", name, "error", configErr)
+		os.Exit(1)
+	}
+
+	log.InfoContext(ctx, "Starting app", "name", name, "config", config.LogValue(appConfig))
 
 	// We call the callback so it can return errors and we can handle it just here.
 	// Also makes it easier to test starting the app if needed, because tests don't handle os.Exit well.
-	if err := start(ctx, log, name, startCallback); err != nil {
+	if err := run(ctx, log, name, opts...); err != nil {
 		log.ErrorContext(ctx, "Error starting app", "name", name, "error", err)
 		os.Exit(1)
 	}
@@ -58,29 +146,76 @@ func Start(startCallback StartFunc) {
 	log.InfoContext(ctx, "Stopped app", "name", name)
 }
 
-func start(ctx context.Context, log *slog.Logger, name string, startCallback StartFunc) error {
-	otelShutdown, err := otelconfig.ConfigureOpenTelemetry(
-		otelconfig.WithServiceName(name), otelconfig.WithServiceVersion(getVersion()),
-		otelconfig.WithMetricsEnabled(false),
-		otelconfig.WithExporterProtocol(otelconfig.ProtocolHTTPProto), otelconfig.WithExporterEndpoint("https://api.honeycomb.io"),
-	)
+// Run the app like [Start], but with the given context, logger, and name, and without signal handling and [os.Exit].
+// It returns when ctx is done and the app has stopped, with the error that stopping the app resulted in, if any.
+// See [maragu.dev/glue/apptest] for running an app in tests.
+func Run(ctx context.Context, log *slog.Logger, name string, startCallback StartFunc, opts ...StartOption) error {
+	return start(ctx, log, name, startCallback, opts...)
+}
+
+func start(ctx context.Context, log *slog.Logger, name string, startCallback StartFunc, opts ...StartOption) error {
+	var c startConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	var configErr error
+	for _, v := range c.configs {
+		configErr = errors.Join(configErr, loadConfig(v))
+	}
+	if configErr != nil {
+		return errors.Wrap(configErr, "error loading config")
+	}
+	for _, v := range c.configs {
+		log.InfoContext(ctx, "Loaded config", "config", configLogValue(v))
+	}
+
+	otelShutdown, err := configureOpenTelemetry(name, c)
 	if err != nil {
 		return errors.Wrap(err, "error configuring open telemetry")
 	}
 	defer otelShutdown()
 
+	// Components can cancel the app context if they fail while running.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	lc := newLifecycle(log, cancel)
+
 	// An error group is used to start and wait for multiple goroutines that can each fail with an error.
 	eg, ctx := errgroup.WithContext(ctx)
 
-	if err := startCallback(ctx, log, eg); err != nil {
+	if err := startCallback(ctx, log, &group{Group: eg, lc: lc}); err != nil {
 		return err
 	}
 
+	if err := lc.start(ctx); err != nil {
+		cancel(err)
+		return errors.Join(err, lc.stop(ctx), eg.Wait())
+	}
+
+	if c.onStarted != nil {
+		c.onStarted()
+	}
+
+	reloadDone := make(chan struct{})
+	go func() {
+		defer close(reloadDone)
+		handleReloads(ctx, log, c, lc)
+	}()
+
 	// Wait for the context to be done, which happens when the user sends a SIGTERM or SIGINT signal.
 	<-ctx.Done()
+	<-reloadDone
 	log.InfoContext(ctx, "Stopping app", "name", name)
 
-	return eg.Wait()
+	if c.health != nil {
+		c.health.Shutdown()
+	}
+
+	// Components are stopped in reverse dependency order, while goroutines started directly in the error group stop on their own.
+	stopErr := lc.stop(ctx)
+
+	return errors.Join(eg.Wait(), stopErr)
 }
 
 func getVersion() string {
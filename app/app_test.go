@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"maragu.dev/is"
+
+	"maragu.dev/glue/health"
 )
 
 func TestStart(t *testing.T) {
@@ -89,3 +91,21 @@ func TestStart(t *testing.T) {
 		is.NotError(t, err)
 	})
 }
+
+func TestWithHealth(t *testing.T) {
+	t.Run("fails readiness when the app stops", func(t *testing.T) {
+		r := health.NewRegistry(health.NewRegistryOptions{})
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			is.True(t, !r.IsShuttingDown())
+			cancel()
+			return nil
+		}
+
+		err := start(ctx, slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterNone), WithHealth(r))
+		is.NotError(t, err)
+		is.True(t, r.IsShuttingDown())
+	})
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+
+	"maragu.dev/errors"
+)
+
+// Command run by [Commands], selected by the first command line argument.
+// Exactly one of Start and Run must be set.
+type Command struct {
+	Name string
+
+	// Usage is a one-line description of the command and its arguments, shown in the help output.
+	Usage string
+
+	// Start is for long-running commands, and is started like with [Start], running until the app is stopped.
+	Start StartFunc
+
+	// Run is for one-off commands, and is given the remaining command line arguments.
+	Run func(ctx context.Context, log *slog.Logger, args []string) error
+}
+
+// Commands lets one app binary run in different roles, selected by the first command line argument.
+// The built-in serve command starts the app with the Serve [StartFunc], and is the default if no command is given.
+//
+// Register other commands with [Commands.Register], such as the migrate and jobs commands
+// from [maragu.dev/glue/app/commands].
+type Commands struct {
+	commands map[string]Command
+	out      io.Writer
+	serve    StartFunc
+}
+
+type NewCommandsOptions struct {
+	// Serve starts the whole app, for the serve command.
+	Serve StartFunc
+}
+
+// NewCommands with the built-in serve command.
+func NewCommands(opts NewCommandsOptions) *Commands {
+	c := &Commands{
+		commands: map[string]Command{},
+		out:      os.Stdout,
+		serve:    opts.Serve,
+	}
+
+	c.Register(Command{
+		Name:  "serve",
+		Usage: "Start the app (default)",
+		Start: c.startServe,
+	})
+
+	return c
+}
+
+// Register a command, replacing any existing command with the same name.
+func (c *Commands) Register(cmd Command) {
+	if cmd.Name == "" {
+		panic("command name must not be empty")
+	}
+	if (cmd.Start == nil) == (cmd.Run == nil) {
+		panic("exactly one of Start and Run must be set on command " + cmd.Name)
+	}
+	c.commands[cmd.Name] = cmd
+}
+
+// Start the app like [Start], but run the command given by the first command line argument.
+func (c *Commands) Start(opts ...StartOption) {
+	main(func(ctx context.Context, log *slog.Logger, name string, opts ...StartOption) error {
+		return c.run(ctx, log, name, os.Args[1:], opts...)
+	}, opts...)
+}
+
+// run the command given by args[0], or serve if args is empty.
+func (c *Commands) run(ctx context.Context, log *slog.Logger, name string, args []string, opts ...StartOption) error {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.printUsage()
+		return nil
+	}
+
+	cmd, ok := c.commands[args[0]]
+	if !ok {
+		c.printUsage()
+		return errors.Newf("unknown command %v", args[0])
+	}
+
+	log.InfoContext(ctx, "Running command", "command", cmd.Name)
+
+	if cmd.Start != nil {
+		return Run(ctx, log, name, cmd.Start, opts...)
+	}
+
+	// One-off commands also run within the app, so they get config and tracing, and the app stops when they return
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return Run(ctx, log, name, func(ctx context.Context, log *slog.Logger, eg Goer) error {
+		eg.Go(func() error {
+			defer cancel()
+			return cmd.Run(ctx, log, args[1:])
+		})
+		return nil
+	}, opts...)
+}
+
+func (c *Commands) printUsage() {
+	var names []string
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	_, _ = fmt.Fprintln(c.out, "Commands:")
+	for _, name := range names {
+		_, _ = fmt.Fprintf(c.out, "  %v %v\n", name, c.commands[name].Usage)
+	}
+}
+
+func (c *Commands) startServe(ctx context.Context, log *slog.Logger, eg Goer) error {
+	if c.serve == nil {
+		return errors.New("serve command not configured")
+	}
+	return c.serve(ctx, log, eg)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+)
+
+func TestCommands(t *testing.T) {
+	t.Run("runs serve by default", func(t *testing.T) {
+		var served bool
+		c := NewCommands(NewCommandsOptions{
+			Serve: func(ctx context.Context, log *slog.Logger, eg Goer) error {
+				served = true
+				return nil
+			},
+		})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		err := c.run(ctx, slog.New(slog.DiscardHandler), "test", nil, WithExporter(ExporterNone))
+		is.NotError(t, err)
+		is.True(t, served)
+	})
+
+	t.Run("runs a registered command with the remaining arguments, and stops when it returns", func(t *testing.T) {
+		var args []string
+		c := NewCommands(NewCommandsOptions{})
+		c.Register(Command{
+			Name: "seed",
+			Run: func(ctx context.Context, log *slog.Logger, a []string) error {
+				args = a
+				return nil
+			},
+		})
+
+		err := c.run(t.Context(), slog.New(slog.DiscardHandler), "test", []string{"seed", "-n", "10"}, WithExporter(ExporterNone))
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"-n", "10"}, args)
+	})
+
+	t.Run("returns the error from a command", func(t *testing.T) {
+		c := NewCommands(NewCommandsOptions{})
+		c.Register(Command{
+			Name: "fail",
+			Run: func(ctx context.Context, log *slog.Logger, args []string) error {
+				return errors.New("oh no")
+			},
+		})
+
+		err := c.run(t.Context(), slog.New(slog.DiscardHandler), "test", []string{"fail"}, WithExporter(ExporterNone))
+		is.True(t, err != nil)
+		is.Equal(t, "oh no", err.Error())
+	})
+
+	t.Run("returns an error and prints usage for unknown commands", func(t *testing.T) {
+		var b bytes.Buffer
+		c := NewCommands(NewCommandsOptions{})
+		c.out = &b
+
+		err := c.run(t.Context(), slog.New(slog.DiscardHandler), "test", []string{"dance"}, WithExporter(ExporterNone))
+		is.True(t, err != nil)
+		is.Equal(t, "unknown command dance", err.Error())
+		is.True(t, strings.Contains(b.String(), "serve Start the app (default)"))
+	})
+}
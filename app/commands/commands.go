@@ -0,0 +1,205 @@
+// Package commands has [app.Command]s for the database and job queues, to register with [app.Commands.Register].
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/app"
+	"maragu.dev/glue/jobs"
+	"maragu.dev/glue/sql"
+)
+
+type Options struct {
+	// JobRunners for [sql.Helper.JobsQ] and [sql.Helper.JobsQCPU], with jobs registered, for the jobs work command.
+	// Either runner may be nil if the app doesn't use the queue.
+	JobRunners func(log *slog.Logger, h *sql.Helper) (q, qCPU *jobs.Runner)
+
+	// SQLHelper for the migrate and jobs commands. It is connected before use.
+	SQLHelper func(log *slog.Logger) *sql.Helper
+
+	out io.Writer
+}
+
+// Migrate command to migrate the database with [sql.Helper.MigrateUp] and [sql.Helper.MigrateDown],
+// or show which migrations are applied:
+//   - migrate up|down|status
+func Migrate(opts Options) app.Command {
+	if opts.out == nil {
+		opts.out = os.Stdout
+	}
+
+	return app.Command{
+		Name:  "migrate",
+		Usage: "up|down|status: Migrate the database up or down, or show migration status",
+		Run:   opts.runMigrate,
+	}
+}
+
+// Jobs command to run only the job runners, or create a job:
+//   - jobs work: only run the job runners for [sql.Helper.JobsQ] and [sql.Helper.JobsQCPU].
+//   - jobs enqueue [-cpu] name [body]: create a job on [sql.Helper.JobsQ], or [sql.Helper.JobsQCPU] with -cpu.
+func Jobs(opts Options) app.Command {
+	if opts.out == nil {
+		opts.out = os.Stdout
+	}
+
+	return app.Command{
+		Name:  "jobs",
+		Usage: "work | enqueue [-cpu] name [body]: Run only the job runners, or create a job",
+		Run:   opts.runJobs,
+	}
+}
+
+// connectSQLHelper from the SQLHelper option.
+func (o Options) connectSQLHelper(ctx context.Context, log *slog.Logger) (*sql.Helper, error) {
+	if o.SQLHelper == nil {
+		return nil, errors.New("sql helper not configured")
+	}
+
+	h := o.SQLHelper(log)
+	if err := h.Connect(ctx); err != nil {
+		return nil, errors.Wrap(err, "error connecting to database")
+	}
+	return h, nil
+}
+
+// closeSQLHelper from [Options.connectSQLHelper] when the command is done, logging any error.
+func closeSQLHelper(ctx context.Context, log *slog.Logger, h *sql.Helper) {
+	if err := h.Close(); err != nil {
+		log.ErrorContext(ctx, "Error closing SQL helper", "error", err)
+	}
+}
+
+func (o Options) runMigrate(ctx context.Context, log *slog.Logger, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: migrate up|down|status")
+	}
+
+	h, err := o.connectSQLHelper(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeSQLHelper(ctx, log, h)
+
+	switch args[0] {
+	case "up":
+		if err := h.MigrateUp(ctx); err != nil {
+			return err
+		}
+		log.InfoContext(ctx, "Migrated up")
+
+	case "down":
+		if err := h.MigrateDown(ctx); err != nil {
+			return err
+		}
+		log.InfoContext(ctx, "Migrated down")
+
+	case "status":
+		ms, err := h.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range ms {
+			status := "pending"
+			if m.Applied {
+				status = "applied"
+			}
+			_, _ = fmt.Fprintf(o.out, "%v %v\n", m.Version, status)
+		}
+
+	default:
+		return errors.Newf("unknown migrate command %v", args[0])
+	}
+
+	return nil
+}
+
+func (o Options) runJobs(ctx context.Context, log *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: jobs work | jobs enqueue [-cpu] name [body]")
+	}
+
+	switch args[0] {
+	case "work":
+		return o.runJobsWork(ctx, log)
+	case "enqueue":
+		return o.runJobsEnqueue(ctx, log, args[1:])
+	default:
+		return errors.Newf("unknown jobs command %v", args[0])
+	}
+}
+
+// runJobsWork runs only the job runners, until ctx is done.
+func (o Options) runJobsWork(ctx context.Context, log *slog.Logger) error {
+	if o.JobRunners == nil {
+		return errors.New("job runners not configured")
+	}
+
+	h, err := o.connectSQLHelper(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeSQLHelper(ctx, log, h)
+
+	q, qCPU := o.JobRunners(log, h)
+
+	var wg sync.WaitGroup
+	for _, r := range []struct {
+		name   string
+		runner *jobs.Runner
+	}{
+		{"jobs", q},
+		{"jobs-cpu", qCPU},
+	} {
+		if r.runner == nil {
+			continue
+		}
+		wg.Go(func() {
+			jobs.Start(ctx, r.runner, nil, r.name)
+		})
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (o Options) runJobsEnqueue(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("jobs enqueue", flag.ContinueOnError)
+	fs.SetOutput(o.out)
+	cpu := fs.Bool("cpu", false, "enqueue on the CPU-bound jobs queue")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return errors.New("usage: jobs enqueue [-cpu] name [body]")
+	}
+	name := fs.Arg(0)
+	body := fs.Arg(1)
+
+	h, err := o.connectSQLHelper(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeSQLHelper(ctx, log, h)
+
+	q, queueName := h.JobsQ, "jobs"
+	if *cpu {
+		q, queueName = h.JobsQCPU, "jobs-cpu"
+	}
+
+	if err := jobs.Create(ctx, q, name, jobs.Message{Body: []byte(body)}); err != nil {
+		return errors.Wrap(err, "error creating job %v", name)
+	}
+	log.InfoContext(ctx, "Enqueued job", "name", name, "queue", queueName)
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"maragu.dev/is"
+
+	"maragu.dev/glue/sql"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Run("returns an error if the sql helper is not configured", func(t *testing.T) {
+		cmd := Migrate(Options{})
+
+		err := cmd.Run(t.Context(), slog.New(slog.DiscardHandler), []string{"up"})
+		is.True(t, err != nil)
+		is.Equal(t, "sql helper not configured", err.Error())
+	})
+
+	t.Run("migrates up and shows status", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.db")
+
+		var b bytes.Buffer
+		cmd := Migrate(Options{
+			SQLHelper: func(log *slog.Logger) *sql.Helper {
+				return sql.NewHelper(sql.NewHelperOptions{Log: log, SQLite: sql.SQLiteOptions{Path: path}})
+			},
+			out: &b,
+		})
+
+		err := cmd.Run(t.Context(), slog.New(slog.DiscardHandler), []string{"status"})
+		is.NotError(t, err)
+		is.True(t, strings.Contains(b.String(), "1747220180-migrations pending"))
+
+		err = cmd.Run(t.Context(), slog.New(slog.DiscardHandler), []string{"up"})
+		is.NotError(t, err)
+
+		b.Reset()
+		err = cmd.Run(t.Context(), slog.New(slog.DiscardHandler), []string{"status"})
+		is.NotError(t, err)
+		is.True(t, strings.Contains(b.String(), "1747220180-migrations applied"))
+	})
+}
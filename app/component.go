@@ -0,0 +1,307 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+	"maragu.dev/errors"
+)
+
+// Component of the app, which [Start] starts in dependency order and stops in reverse dependency order.
+// All funcs are optional.
+type Component struct {
+	// Name of the component, which must be unique. Used in logs, traces, and DependsOn.
+	Name string
+
+	// DependsOn names of other components, which are started before and stopped after this one.
+	DependsOn []string
+
+	// Start the component. It should not block, and is called after all dependencies have been started.
+	Start func(ctx context.Context) error
+
+	// Run the component, blocking until ctx is cancelled, like [maragu.dev/glue/http.Server.Start].
+	// It is called after Start returns. Its context is cancelled when the component is stopped,
+	// not when the app starts shutting down, so dependents get to stop first.
+	Run func(ctx context.Context) error
+
+	// Stop the component after Run has returned, for example to close a database connection.
+	Stop func(ctx context.Context) error
+
+	// Reload the component's settings, called on SIGHUP after the app config has been reloaded, see [WithOnReload].
+	// It's only called while the component is started.
+	Reload func(ctx context.Context) error
+
+	// StopTimeout for waiting on Run to return and Stop to finish. Defaults to one minute.
+	StopTimeout time.Duration
+}
+
+// group is the [Goer] given to a [StartFunc].
+type group struct {
+	*errgroup.Group
+	lc *lifecycle
+}
+
+func (g *group) Add(c Component) {
+	g.lc.add(c)
+}
+
+// lifecycle of [Component]s.
+type lifecycle struct {
+	cancel      context.CancelCauseFunc
+	components  []Component
+	errs        []error
+	errsLock    sync.Mutex
+	log         *slog.Logger
+	started     []*runningComponent
+	startedLock sync.Mutex
+	tracer      trace.Tracer
+}
+
+type runningComponent struct {
+	c         Component
+	cancelRun context.CancelFunc
+	runDone   chan struct{}
+}
+
+// newLifecycle which calls cancel with the error if a component's Run fails.
+func newLifecycle(log *slog.Logger, cancel context.CancelCauseFunc) *lifecycle {
+	return &lifecycle{
+		cancel: cancel,
+		log:    log,
+		tracer: otel.Tracer("maragu.dev/glue/app"),
+	}
+}
+
+func (l *lifecycle) add(c Component) {
+	if c.Name == "" {
+		panic("component name must not be empty")
+	}
+	if c.StopTimeout == 0 {
+		c.StopTimeout = time.Minute
+	}
+	l.components = append(l.components, c)
+}
+
+// order components so that dependencies come before dependents, keeping the order they were added in otherwise.
+func (l *lifecycle) order() ([]Component, error) {
+	byName := map[string]Component{}
+	for _, c := range l.components {
+		if _, ok := byName[c.Name]; ok {
+			return nil, errors.Newf("duplicate component %v", c.Name)
+		}
+		byName[c.Name] = c
+	}
+
+	for _, c := range l.components {
+		for _, dep := range c.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, errors.Newf("component %v depends on unknown component %v", c.Name, dep)
+			}
+		}
+	}
+
+	var ordered []Component
+	done := map[string]bool{}
+	for len(ordered) < len(l.components) {
+		progressed := false
+		for _, c := range l.components {
+			if done[c.Name] {
+				continue
+			}
+			if !slices.ContainsFunc(c.DependsOn, func(dep string) bool { return !done[dep] }) {
+				ordered = append(ordered, c)
+				done[c.Name] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			var remaining []string
+			for _, c := range l.components {
+				if !done[c.Name] {
+					remaining = append(remaining, c.Name)
+				}
+			}
+			return nil, errors.Newf("dependency cycle between components %v", remaining)
+		}
+	}
+
+	return ordered, nil
+}
+
+// start all components in dependency order, stopping early without error if ctx is done.
+// If starting a component fails, the error is returned, and already started components should be stopped with [lifecycle.stop].
+func (l *lifecycle) start(ctx context.Context) error {
+	ordered, err := l.order()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range ordered {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err := l.startComponent(ctx, c); err != nil {
+			return errors.Wrap(err, "error starting component %v", c.Name)
+		}
+	}
+
+	return nil
+}
+
+func (l *lifecycle) startComponent(ctx context.Context, c Component) error {
+	ctx, span := l.tracer.Start(ctx, "app.start", trace.WithAttributes(attribute.String("app.component", c.Name)))
+	defer span.End()
+
+	l.log.InfoContext(ctx, "Starting component", "name", c.Name)
+
+	if c.Start != nil {
+		if err := c.Start(ctx); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "start failed")
+			return err
+		}
+	}
+
+	rc := &runningComponent{c: c, runDone: make(chan struct{})}
+	l.startedLock.Lock()
+	l.started = append(l.started, rc)
+	l.startedLock.Unlock()
+
+	if c.Run == nil {
+		close(rc.runDone)
+		return nil
+	}
+
+	// The run context is detached from the app context, so it's only cancelled when this component is stopped
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	rc.cancelRun = cancel
+
+	go func() {
+		defer close(rc.runDone)
+		if err := c.Run(runCtx); err != nil && !(errors.Is(err, context.Canceled) && runCtx.Err() != nil) {
+			err = errors.Wrap(err, "error running component %v", c.Name)
+			l.addErr(err)
+			l.cancel(err)
+		}
+	}()
+
+	return nil
+}
+
+// stop all started components in reverse dependency order.
+// Failures and timeouts are logged, traced, and returned joined.
+func (l *lifecycle) stop(ctx context.Context) error {
+	ctx = context.WithoutCancel(ctx)
+
+	l.startedLock.Lock()
+	started := l.started
+	l.started = nil
+	l.startedLock.Unlock()
+
+	for _, rc := range slices.Backward(started) {
+		if err := l.stopComponent(ctx, rc); err != nil {
+			l.log.ErrorContext(ctx, "Error stopping component", "name", rc.c.Name, "error", err)
+			l.addErr(errors.Wrap(err, "error stopping component %v", rc.c.Name))
+		}
+	}
+
+	l.errsLock.Lock()
+	defer l.errsLock.Unlock()
+	return errors.Join(l.errs...)
+}
+
+func (l *lifecycle) stopComponent(ctx context.Context, rc *runningComponent) error {
+	ctx, span := l.tracer.Start(ctx, "app.stop", trace.WithAttributes(attribute.String("app.component", rc.c.Name)))
+	defer span.End()
+
+	l.log.InfoContext(ctx, "Stopping component", "name", rc.c.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, rc.c.StopTimeout)
+	defer cancel()
+
+	if rc.cancelRun != nil {
+		rc.cancelRun()
+	}
+
+	select {
+	case <-rc.runDone:
+	case <-ctx.Done():
+		err := errors.Newf("timed out after %v waiting for component to stop running", rc.c.StopTimeout)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "stop timed out")
+		return err
+	}
+
+	if rc.c.Stop == nil {
+		return nil
+	}
+
+	stopErr := make(chan error, 1)
+	go func() {
+		stopErr <- rc.c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-stopErr:
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "stop failed")
+			return err
+		}
+	case <-ctx.Done():
+		err := errors.Newf("timed out after %v waiting for component to stop", rc.c.StopTimeout)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "stop timed out")
+		return err
+	}
+
+	return nil
+}
+
+// reload all started components in dependency order, returning all errors joined.
+func (l *lifecycle) reload(ctx context.Context) error {
+	l.startedLock.Lock()
+	started := slices.Clone(l.started)
+	l.startedLock.Unlock()
+
+	var errs []error
+	for _, rc := range started {
+		if rc.c.Reload == nil {
+			continue
+		}
+
+		if err := l.reloadComponent(ctx, rc.c); err != nil {
+			errs = append(errs, errors.Wrap(err, "error reloading component %v", rc.c.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *lifecycle) reloadComponent(ctx context.Context, c Component) error {
+	ctx, span := l.tracer.Start(ctx, "app.reload", trace.WithAttributes(attribute.String("app.component", c.Name)))
+	defer span.End()
+
+	l.log.InfoContext(ctx, "Reloading component", "name", c.Name)
+
+	if err := c.Reload(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "reload failed")
+		return err
+	}
+	return nil
+}
+
+func (l *lifecycle) addErr(err error) {
+	l.errsLock.Lock()
+	defer l.errsLock.Unlock()
+	l.errs = append(l.errs, err)
+}
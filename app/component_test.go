@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+)
+
+func TestGoer_Add(t *testing.T) {
+	t.Run("starts components in dependency order and stops them in reverse", func(t *testing.T) {
+		var events []string
+		var lock sync.Mutex
+		record := func(event string) {
+			lock.Lock()
+			defer lock.Unlock()
+			events = append(events, event)
+		}
+
+		newComponent := func(name string, dependsOn ...string) Component {
+			return Component{
+				Name:      name,
+				DependsOn: dependsOn,
+				Start: func(ctx context.Context) error {
+					record("start " + name)
+					return nil
+				},
+				Run: func(ctx context.Context) error {
+					<-ctx.Done()
+					record("run done " + name)
+					return nil
+				},
+				Stop: func(ctx context.Context) error {
+					record("stop " + name)
+					return nil
+				},
+			}
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			eg.Add(newComponent("http", "jobs", "db"))
+			eg.Add(newComponent("jobs", "db"))
+			eg.Add(newComponent("db"))
+			eg.Add(Component{
+				Name:      "trigger",
+				DependsOn: []string{"http"},
+				Start: func(ctx context.Context) error {
+					cancel()
+					return nil
+				},
+			})
+			return nil
+		}
+
+		err := start(ctx, slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterNone))
+		is.NotError(t, err)
+
+		is.EqualSlice(t, []string{
+			"start db", "start jobs", "start http",
+			"run done http", "stop http",
+			"run done jobs", "stop jobs",
+			"run done db", "stop db",
+		}, events)
+	})
+
+	t.Run("returns error on unknown dependency", func(t *testing.T) {
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			eg.Add(Component{Name: "http", DependsOn: []string{"db"}})
+			return nil
+		}
+
+		err := start(t.Context(), slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterNone))
+		is.True(t, err != nil)
+		is.Equal(t, "component http depends on unknown component db", err.Error())
+	})
+
+	t.Run("returns error on dependency cycle", func(t *testing.T) {
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			eg.Add(Component{Name: "a", DependsOn: []string{"b"}})
+			eg.Add(Component{Name: "b", DependsOn: []string{"a"}})
+			return nil
+		}
+
+		err := start(t.Context(), slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterNone))
+		is.True(t, err != nil)
+		is.Equal(t, "dependency cycle between components [a b]", err.Error())
+	})
+
+	t.Run("stops already started components if a component fails to start", func(t *testing.T) {
+		var stopped bool
+
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			eg.Add(Component{Name: "db", Stop: func(ctx context.Context) error {
+				stopped = true
+				return nil
+			}})
+			eg.Add(Component{Name: "http", DependsOn: []string{"db"}, Start: func(ctx context.Context) error {
+				return errors.New("oh no")
+			}})
+			return nil
+		}
+
+		err := start(t.Context(), slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterNone))
+		is.True(t, err != nil)
+		is.Equal(t, "error starting component http: oh no", err.Error())
+		is.True(t, stopped)
+	})
+
+	t.Run("stops the app if a component fails while running", func(t *testing.T) {
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			eg.Add(Component{Name: "jobs", Run: func(ctx context.Context) error {
+				return errors.New("oh no")
+			}})
+			return nil
+		}
+
+		err := start(t.Context(), slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterNone))
+		is.True(t, err != nil)
+		is.Equal(t, "error running component jobs: oh no", err.Error())
+	})
+
+	t.Run("returns stop errors and timeouts, and continues stopping other components", func(t *testing.T) {
+		var stopped bool
+		ctx, cancel := context.WithCancel(t.Context())
+
+		// Keeps the http component running past its stop timeout
+		block := make(chan struct{})
+		t.Cleanup(func() {
+			close(block)
+		})
+
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			eg.Add(Component{Name: "db", Stop: func(ctx context.Context) error {
+				stopped = true
+				return nil
+			}})
+			eg.Add(Component{Name: "jobs", DependsOn: []string{"db"}, Stop: func(ctx context.Context) error {
+				return errors.New("oh no")
+			}})
+			eg.Add(Component{Name: "http", DependsOn: []string{"jobs"}, StopTimeout: time.Millisecond, Run: func(ctx context.Context) error {
+				<-block
+				return nil
+			}})
+			eg.Add(Component{Name: "trigger", DependsOn: []string{"http"}, Start: func(ctx context.Context) error {
+				cancel()
+				return nil
+			}})
+			return nil
+		}
+
+		err := start(ctx, slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterNone))
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "error stopping component http: timed out after 1ms waiting for component to stop running"))
+		is.True(t, strings.Contains(err.Error(), "error stopping component jobs: oh no"))
+		is.True(t, stopped)
+	})
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/honeycombio/otel-config-go/otelconfig"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"maragu.dev/env"
+	"maragu.dev/errors"
+)
+
+// Exporter for OpenTelemetry traces, set with [WithExporter] or the OTEL_TRACES_EXPORTER environment variable.
+type Exporter string
+
+const (
+	// ExporterHoneycomb exports over OTLP HTTP/proto to https://api.honeycomb.io. This is the default.
+	ExporterHoneycomb = Exporter("honeycomb")
+
+	// ExporterOTLPHTTP exports over OTLP HTTP/proto to the endpoint from [WithExporterEndpoint] or OTEL_EXPORTER_OTLP_ENDPOINT.
+	ExporterOTLPHTTP = Exporter("otlp-http")
+
+	// ExporterOTLPGRPC exports over OTLP gRPC to the endpoint from [WithExporterEndpoint] or OTEL_EXPORTER_OTLP_ENDPOINT.
+	ExporterOTLPGRPC = Exporter("otlp-grpc")
+
+	// ExporterStdout pretty-prints spans to stdout, which is useful in development.
+	ExporterStdout = Exporter("stdout")
+
+	// ExporterFile writes spans as JSON lines to the file from [WithExporterFilePath] or OTEL_EXPORTER_FILE_PATH.
+	ExporterFile = Exporter("file")
+
+	// ExporterNone disables tracing.
+	ExporterNone = Exporter("none")
+)
+
+const honeycombEndpoint = "https://api.honeycomb.io"
+
+// WithExporter sets the [Exporter] for traces, overriding the OTEL_TRACES_EXPORTER environment variable.
+func WithExporter(e Exporter) StartOption {
+	return func(c *startConfig) {
+		c.exporter = e
+	}
+}
+
+// WithExporterEndpoint sets the endpoint for [ExporterOTLPHTTP] and [ExporterOTLPGRPC].
+// Note that OTEL_EXPORTER_OTLP_ENDPOINT still takes precedence if set.
+func WithExporterEndpoint(url string) StartOption {
+	return func(c *startConfig) {
+		c.exporterEndpoint = url
+	}
+}
+
+// WithExporterFilePath sets the file path for [ExporterFile], overriding the OTEL_EXPORTER_FILE_PATH environment variable.
+func WithExporterFilePath(path string) StartOption {
+	return func(c *startConfig) {
+		c.exporterFilePath = path
+	}
+}
+
+// WithMetrics enables OpenTelemetry metrics, which are exported alongside traces for [ExporterHoneycomb],
+// [ExporterOTLPHTTP], and [ExporterOTLPGRPC]. The other exporters don't export metrics.
+// In tests, use [maragu.dev/glue/oteltest.NewMetricReader] to read metrics instead.
+func WithMetrics() StartOption {
+	return func(c *startConfig) {
+		c.metrics = true
+	}
+}
+
+// getExporter from the config, falling back to the OTEL_TRACES_EXPORTER environment variable and then [ExporterHoneycomb].
+// The standard OTEL_TRACES_EXPORTER values "otlp", "console", and "none" are supported,
+// where "otlp" uses OTEL_EXPORTER_OTLP_PROTOCOL to choose between gRPC and HTTP.
+func (c startConfig) getExporter() (Exporter, error) {
+	if c.exporter != "" {
+		return c.exporter, nil
+	}
+
+	switch v := env.GetStringOrDefault("OTEL_TRACES_EXPORTER", string(ExporterHoneycomb)); v {
+	case "otlp":
+		if env.GetStringOrDefault("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf") == "grpc" {
+			return ExporterOTLPGRPC, nil
+		}
+		return ExporterOTLPHTTP, nil
+	case "console":
+		return ExporterStdout, nil
+	case "":
+		return ExporterHoneycomb, nil
+	case string(ExporterHoneycomb), string(ExporterOTLPHTTP), string(ExporterOTLPGRPC), string(ExporterStdout), string(ExporterFile), string(ExporterNone):
+		return Exporter(v), nil
+	default:
+		return "", errors.Newf("unknown trace exporter %v", v)
+	}
+}
+
+// configureOpenTelemetry according to the chosen [Exporter], returning a shutdown function.
+// OTLP exporters are set up through [otelconfig], so the standard OTEL_* environment variables apply.
+func configureOpenTelemetry(name string, c startConfig) (func(), error) {
+	exporter, err := c.getExporter()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []otelconfig.Option{
+		otelconfig.WithServiceName(name), otelconfig.WithServiceVersion(getVersion()),
+		otelconfig.WithMetricsEnabled(c.metrics),
+	}
+
+	switch exporter {
+	case ExporterHoneycomb:
+		opts = append(opts, otelconfig.WithExporterProtocol(otelconfig.ProtocolHTTPProto), otelconfig.WithExporterEndpoint(honeycombEndpoint))
+		return otelconfig.ConfigureOpenTelemetry(opts...)
+
+	case ExporterOTLPHTTP, ExporterOTLPGRPC:
+		protocol := otelconfig.ProtocolHTTPProto
+		if exporter == ExporterOTLPGRPC {
+			protocol = otelconfig.ProtocolGRPC
+		}
+		opts = append(opts, otelconfig.WithExporterProtocol(protocol))
+		if c.exporterEndpoint != "" {
+			opts = append(opts, otelconfig.WithExporterEndpoint(c.exporterEndpoint))
+		}
+		return otelconfig.ConfigureOpenTelemetry(opts...)
+
+	case ExporterStdout:
+		return configureWriterExporter(name, os.Stdout, stdouttrace.WithPrettyPrint())
+
+	case ExporterFile:
+		path := c.exporterFilePath
+		if path == "" {
+			path = env.GetStringOrDefault("OTEL_EXPORTER_FILE_PATH", "traces.jsonl")
+		}
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, errors.Wrap(err, "error opening trace file")
+		}
+
+		shutdown, err := configureWriterExporter(name, f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return func() {
+			shutdown()
+			_ = f.Close()
+		}, nil
+
+	case ExporterNone:
+		return func() {}, nil
+
+	default:
+		return nil, errors.Newf("unknown trace exporter %v", exporter)
+	}
+}
+
+// configureWriterExporter sets up a global tracer provider that writes spans to w.
+func configureWriterExporter(name string, w io.Writer, opts ...stdouttrace.Option) (func(), error) {
+	exp, err := stdouttrace.New(append([]stdouttrace.Option{stdouttrace.WithWriter(w)}, opts...)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating trace exporter")
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(name),
+		semconv.ServiceVersion(getVersion()),
+	)
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func() {
+		_ = tp.Shutdown(context.Background())
+	}, nil
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"maragu.dev/is"
+)
+
+func TestStartConfig_getExporter(t *testing.T) {
+	t.Run("uses the option over the environment", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "console")
+
+		exporter, err := startConfig{exporter: ExporterNone}.getExporter()
+		is.NotError(t, err)
+		is.Equal(t, ExporterNone, exporter)
+	})
+
+	t.Run("defaults to honeycomb", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+		exporter, err := startConfig{}.getExporter()
+		is.NotError(t, err)
+		is.Equal(t, ExporterHoneycomb, exporter)
+	})
+
+	tests := []struct {
+		value    string
+		protocol string
+		expected Exporter
+	}{
+		{value: "otlp", expected: ExporterOTLPHTTP},
+		{value: "otlp", protocol: "http/protobuf", expected: ExporterOTLPHTTP},
+		{value: "otlp", protocol: "grpc", expected: ExporterOTLPGRPC},
+		{value: "console", expected: ExporterStdout},
+		{value: "stdout", expected: ExporterStdout},
+		{value: "file", expected: ExporterFile},
+		{value: "none", expected: ExporterNone},
+		{value: "honeycomb", expected: ExporterHoneycomb},
+	}
+
+	for _, test := range tests {
+		t.Run("maps "+test.value+" "+test.protocol+" from the environment", func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", test.value)
+			t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", test.protocol)
+
+			exporter, err := startConfig{}.getExporter()
+			is.NotError(t, err)
+			is.Equal(t, test.expected, exporter)
+		})
+	}
+
+	t.Run("errors on unknown exporter", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "carrier-pigeon")
+
+		_, err := startConfig{}.getExporter()
+		is.Equal(t, "unknown trace exporter carrier-pigeon", err.Error())
+	})
+}
+
+func TestStart_exporter(t *testing.T) {
+	t.Run("writes spans to a file with the file exporter", func(t *testing.T) {
+		previous := otel.GetTracerProvider()
+		t.Cleanup(func() {
+			otel.SetTracerProvider(previous)
+		})
+
+		path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		startFunc := func(ctx context.Context, log *slog.Logger, eg Goer) error {
+			_, span := otel.Tracer("test").Start(ctx, "test-span")
+			span.End()
+			cancel()
+			return nil
+		}
+
+		err := start(ctx, slog.New(slog.DiscardHandler), "test", startFunc, WithExporter(ExporterFile), WithExporterFilePath(path))
+		is.NotError(t, err)
+
+		traces, err := os.ReadFile(path)
+		is.NotError(t, err)
+		is.True(t, strings.Contains(string(traces), `"Name":"test-span"`))
+	})
+}
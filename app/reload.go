@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/config"
+	gluelog "maragu.dev/glue/log"
+)
+
+// envPaths are loaded by [config.Load] for the app config, both at startup and on reload.
+// This loads from .env in development and /run/secrets/env when run in a container through Docker compose.
+var envPaths = []string{".env", "/run/secrets/env"}
+
+// loader is satisfied by [config.Reloadable].
+type loader interface {
+	Load(paths ...string) error
+}
+
+// loadConfig v with its own Load method if it's a [config.Reloadable], and [config.Load] otherwise.
+// The [envPaths] are read again first, so changes to them are picked up on reload.
+func loadConfig(v any) error {
+	if l, ok := v.(loader); ok {
+		return l.Load(envPaths...)
+	}
+	return config.Load(v, envPaths...)
+}
+
+// configLogValue of v with its own LogValue method if it's a [config.Reloadable], and [config.LogValue] otherwise.
+func configLogValue(v any) slog.Value {
+	if lv, ok := v.(slog.LogValuer); ok {
+		return lv.LogValue()
+	}
+	return config.LogValue(v)
+}
+
+// handleReloads on SIGHUP until ctx is done.
+func handleReloads(ctx context.Context, log *slog.Logger, c startConfig, lc *lifecycle) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if err := reload(ctx, log, c, lc); err != nil {
+				log.ErrorContext(ctx, "Error reloading app", "error", err)
+			}
+		}
+	}
+}
+
+// reload the log level, reloadable configs, reload callbacks, and components, returning all errors joined.
+// Reloading continues past errors, so one bad setting doesn't keep the others from being reloaded.
+func reload(ctx context.Context, log *slog.Logger, c startConfig, lc *lifecycle) error {
+	log.InfoContext(ctx, "Reloading app")
+
+	var errs []error
+
+	if c.logLevel != nil {
+		var appConfig config.App
+		if err := config.Load(&appConfig, envPaths...); err != nil {
+			errs = append(errs, errors.Wrap(err, "error reloading app config"))
+		} else {
+			c.logLevel.Set(gluelog.StringToLevel(appConfig.LogLevel))
+			log.InfoContext(ctx, "Reloaded log level", "level", c.logLevel.Level())
+		}
+	}
+
+	for _, v := range c.configs {
+		if _, ok := v.(loader); !ok {
+			continue
+		}
+		if err := loadConfig(v); err != nil {
+			errs = append(errs, errors.Wrap(err, "error reloading config"))
+			continue
+		}
+		log.InfoContext(ctx, "Reloaded config", "config", configLogValue(v))
+	}
+
+	for _, f := range c.onReload {
+		if err := f(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := lc.reload(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
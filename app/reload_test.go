@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/config"
+)
+
+type reloadableTestConfig struct {
+	Greeting string `env:"TEST_GREETING" default:"hi"`
+}
+
+func TestReload(t *testing.T) {
+	t.Run("reloads the log level, reloadable configs, callbacks, and components", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "info")
+		t.Setenv("TEST_GREETING", "hello")
+
+		var level slog.LevelVar
+		var r config.Reloadable[reloadableTestConfig]
+		var events []string
+
+		lc := newLifecycle(slog.New(slog.DiscardHandler), func(error) {})
+		lc.add(Component{Name: "db", Reload: func(ctx context.Context) error {
+			events = append(events, "reload db")
+			return nil
+		}})
+		lc.add(Component{Name: "http", DependsOn: []string{"db"}})
+		err := lc.start(t.Context())
+		is.NotError(t, err)
+
+		var c startConfig
+		for _, opt := range []StartOption{
+			WithLogLevel(&level),
+			WithConfig(&r),
+			WithOnReload(func(ctx context.Context) error {
+				events = append(events, "on reload "+r.Get().Greeting)
+				return nil
+			}),
+		} {
+			opt(&c)
+		}
+
+		t.Setenv("LOG_LEVEL", "debug")
+		t.Setenv("TEST_GREETING", "howdy")
+
+		err = reload(t.Context(), slog.New(slog.DiscardHandler), c, lc)
+		is.NotError(t, err)
+		is.Equal(t, slog.LevelDebug, level.Level())
+		is.Equal(t, "howdy", r.Get().Greeting)
+		is.EqualSlice(t, []string{"on reload howdy", "reload db"}, events)
+	})
+
+	t.Run("reads the env files again for reloadable configs without a log level", func(t *testing.T) {
+		t.Setenv("TEST_GREETING", "hello")
+
+		path := filepath.Join(t.TempDir(), ".env")
+		previousEnvPaths := envPaths
+		envPaths = []string{path}
+		t.Cleanup(func() {
+			envPaths = previousEnvPaths
+		})
+
+		var r config.Reloadable[reloadableTestConfig]
+		err := r.Load()
+		is.NotError(t, err)
+		is.Equal(t, "hello", r.Get().Greeting)
+
+		err = os.WriteFile(path, []byte("TEST_GREETING=howdy\n"), 0o600)
+		is.NotError(t, err)
+
+		var c startConfig
+		WithConfig(&r)(&c)
+
+		lc := newLifecycle(slog.New(slog.DiscardHandler), func(error) {})
+		err = reload(t.Context(), slog.New(slog.DiscardHandler), c, lc)
+		is.NotError(t, err)
+		is.Equal(t, "howdy", r.Get().Greeting)
+	})
+
+	t.Run("continues past errors and returns them all", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "verbose")
+
+		var level slog.LevelVar
+		var reloaded bool
+
+		lc := newLifecycle(slog.New(slog.DiscardHandler), func(error) {})
+		lc.add(Component{Name: "db", Reload: func(ctx context.Context) error {
+			return errors.New("oh no")
+		}})
+		err := lc.start(t.Context())
+		is.NotError(t, err)
+
+		var c startConfig
+		WithLogLevel(&level)(&c)
+		WithOnReload(func(ctx context.Context) error {
+			reloaded = true
+			return nil
+		})(&c)
+
+		err = reload(t.Context(), slog.New(slog.DiscardHandler), c, lc)
+		is.True(t, err != nil)
+		is.Equal(t, "error reloading app config: LOG_LEVEL must be one of debug, info, warn, error, got verbose\nerror reloading component db: oh no", err.Error())
+		is.Equal(t, slog.LevelInfo, level.Level())
+		is.True(t, reloaded)
+	})
+}
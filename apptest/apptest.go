@@ -0,0 +1,96 @@
+// Package apptest provides a test harness for running apps started with [app.Start] in-process.
+package apptest
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"maragu.dev/glue/app"
+	"maragu.dev/glue/log"
+)
+
+// App running in a test, see [Start].
+type App struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	waited bool
+}
+
+// Start the app for testing with the given [app.StartFunc] and options, like [app.Start] does,
+// with the same logger, OpenTelemetry, and error group setup.
+// Logs are written to the test log, and tracing is disabled unless an exporter is given with [app.WithExporter].
+// Start blocks until the start callback has returned and all components have been started, or starting failed.
+// The app is stopped when the test is done, if not stopped before with [App.Stop],
+// and the test fails if the app stopped with an error that wasn't returned from [App.Stop] or [App.Wait].
+func Start(t *testing.T, startCallback app.StartFunc, opts ...app.StartOption) *App {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(t.Context()))
+
+	a := &App{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	started := make(chan struct{})
+	opts = append([]app.StartOption{app.WithExporter(app.ExporterNone)}, opts...)
+	opts = append(opts, app.WithOnStarted(func() {
+		close(started)
+	}))
+
+	l := log.NewLogger(log.NewLoggerOptions{
+		Level:  slog.LevelDebug,
+		NoTime: true,
+		W:      &testWriter{t: t},
+	})
+
+	go func() {
+		defer close(a.done)
+		a.err = app.Run(ctx, l, "test", startCallback, opts...)
+	}()
+
+	// Errors not already returned from [App.Stop] or [App.Wait] fail the test
+	t.Cleanup(func() {
+		waited := a.waited
+		if err := a.Stop(); err != nil && !waited {
+			t.Error(err)
+		}
+	})
+
+	select {
+	case <-started:
+	case <-a.done:
+	}
+
+	return a
+}
+
+// Stop the app gracefully, like when receiving SIGTERM, and return the error the app stopped with, if any.
+// It's safe to call Stop multiple times.
+func (a *App) Stop() error {
+	a.cancel()
+	return a.Wait()
+}
+
+// Wait for the app to stop on its own, and return the error the app stopped with, if any.
+func (a *App) Wait() error {
+	<-a.done
+	a.waited = true
+	return a.err
+}
+
+// Done is closed when the app has stopped.
+func (a *App) Done() <-chan struct{} {
+	return a.done
+}
+
+type testWriter struct {
+	t *testing.T
+}
+
+func (t *testWriter) Write(p []byte) (n int, err error) {
+	t.t.Log(string(p))
+	return len(p), nil
+}
@@ -0,0 +1,68 @@
+package apptest_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/app"
+	"maragu.dev/glue/apptest"
+)
+
+func TestStart(t *testing.T) {
+	t.Run("starts components and stops them gracefully on stop", func(t *testing.T) {
+		var started, stopped bool
+
+		a := apptest.Start(t, func(ctx context.Context, log *slog.Logger, eg app.Goer) error {
+			eg.Add(app.Component{
+				Name: "test",
+				Start: func(ctx context.Context) error {
+					started = true
+					return nil
+				},
+				Stop: func(ctx context.Context) error {
+					stopped = true
+					return nil
+				},
+			})
+			return nil
+		})
+
+		is.True(t, started)
+		is.True(t, !stopped)
+
+		err := a.Stop()
+		is.NotError(t, err)
+		is.True(t, stopped)
+	})
+
+	t.Run("returns the error from the error group", func(t *testing.T) {
+		a := apptest.Start(t, func(ctx context.Context, log *slog.Logger, eg app.Goer) error {
+			eg.Go(func() error {
+				<-ctx.Done()
+				return errors.New("oh no")
+			})
+			return nil
+		})
+
+		err := a.Stop()
+		is.True(t, err != nil)
+		is.Equal(t, "oh no", err.Error())
+	})
+
+	t.Run("returns the error if the app fails to start", func(t *testing.T) {
+		a := apptest.Start(t, func(ctx context.Context, log *slog.Logger, eg app.Goer) error {
+			return errors.New("oh no")
+		})
+
+		err := a.Wait()
+		is.True(t, err != nil)
+		is.Equal(t, "oh no", err.Error())
+
+		// Stopping again returns the same error
+		is.Equal(t, err, a.Stop())
+	})
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"strings"
+
+	"maragu.dev/errors"
+)
+
+// App config, as used by [maragu.dev/glue/app.Start].
+type App struct {
+	Name      string `env:"APP_NAME" default:"App"`
+	LogJSON   bool   `env:"LOG_JSON" default:"true"`
+	LogLevel  string `env:"LOG_LEVEL" default:"info"`
+	LogNoTime bool   `env:"LOG_NO_TIME" default:"false"`
+}
+
+// Validate satisfies [validator].
+func (c *App) Validate() error {
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+		return nil
+	default:
+		return errors.Newf("LOG_LEVEL must be one of debug, info, warn, error, got %v", c.LogLevel)
+	}
+}
@@ -0,0 +1,260 @@
+// Package config loads typed configuration from environment variables and .env files into structs.
+//
+// Struct fields are bound with tags:
+//   - env:"NAME" binds the field to the environment variable NAME.
+//   - env:"NAME,required" makes it an error if NAME is not set and there is no default.
+//   - env:"NAME,secret" redacts the value in [LogValue].
+//   - default:"value" sets the value used if NAME is not set.
+//
+// Supported field types are strings, bools, ints, uints, floats, [time.Duration], string slices (comma-separated),
+// types implementing [encoding.TextUnmarshaler], and types with those as underlying types.
+// Nested structs without an env tag are loaded recursively.
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"maragu.dev/env"
+	"maragu.dev/errors"
+)
+
+// validator can be implemented by config structs to validate themselves after loading.
+type validator interface {
+	Validate() error
+}
+
+// Load environment variables from the given .env files, and then bind environment variables into the struct pointed to by v.
+// Files that do not exist are ignored. Like [env.Load], variables from the files overwrite those already in the environment.
+// All missing required fields, parse errors, and validation errors are returned together.
+// If v (or any nested struct) has a Validate() error method, it's called after binding.
+func Load(v any, paths ...string) error {
+	for _, path := range paths {
+		if err := loadFile(path); err != nil {
+			return err
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic("config must be a pointer to a struct")
+	}
+
+	return errors.Join(bind(rv.Elem())...)
+}
+
+// loadFile with [env.Load], ignoring files that do not exist.
+func loadFile(path string) error {
+	if err := env.Load(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// bind environment variables into the fields of the struct rv, returning all errors.
+func bind(rv reflect.Value) []error {
+	var errs []error
+
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		tag, ok := parseTag(field)
+		if !ok {
+			if field.Type.Kind() == reflect.Struct && !isTextUnmarshaler(fv) {
+				errs = append(errs, bind(fv)...)
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(tag.name)
+		if !ok {
+			value, ok = field.Tag.Lookup("default")
+		}
+
+		if !ok {
+			if tag.required {
+				errs = append(errs, errors.Newf("%v is required", tag.name))
+			}
+			continue
+		}
+
+		if err := setValue(fv, value); err != nil {
+			errs = append(errs, errors.Wrap(err, "error parsing %v", tag.name))
+		}
+	}
+
+	// Validate even if binding failed, so all errors are reported together
+	if v, ok := rv.Addr().Interface().(validator); ok {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+type tag struct {
+	name     string
+	required bool
+	secret   bool
+}
+
+// parseTag of the form env:"NAME,required,secret".
+func parseTag(field reflect.StructField) (tag, bool) {
+	v, ok := field.Tag.Lookup("env")
+	if !ok || v == "" || v == "-" {
+		return tag{}, false
+	}
+
+	parts := strings.Split(v, ",")
+	t := tag{name: parts[0]}
+	for _, part := range parts[1:] {
+		switch part {
+		case "required":
+			t.required = true
+		case "secret":
+			t.secret = true
+		default:
+			panic("unknown env tag option " + part + " on field " + field.Name)
+		}
+	}
+
+	return t, true
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+func isTextUnmarshaler(fv reflect.Value) bool {
+	_, ok := fv.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// setValue on fv by parsing s according to the field type.
+func setValue(fv reflect.Value, s string) error {
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			panic("unsupported config slice type " + fv.Type().String())
+		}
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			slice.Index(i).SetString(strings.TrimSpace(part))
+		}
+		fv.Set(slice)
+
+	default:
+		panic("unsupported config type " + fv.Type().String())
+	}
+
+	return nil
+}
+
+const redacted = "[redacted]"
+
+// LogValue of the config struct v, for logging the effective configuration.
+// Fields are keyed by their environment variable name, and secret fields are redacted if set.
+func LogValue(v any) slog.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic("config must be a struct or a pointer to a struct")
+	}
+
+	return slog.GroupValue(logAttrs(rv)...)
+}
+
+func logAttrs(rv reflect.Value) []slog.Attr {
+	var attrs []slog.Attr
+
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		tag, ok := parseTag(field)
+		if !ok {
+			if field.Type.Kind() == reflect.Struct && !isTextUnmarshaler(reflect.New(field.Type).Elem()) {
+				attrs = append(attrs, logAttrs(fv)...)
+			}
+			continue
+		}
+
+		if tag.secret {
+			if fv.IsZero() {
+				attrs = append(attrs, slog.String(tag.name, ""))
+			} else {
+				attrs = append(attrs, slog.String(tag.name, redacted))
+			}
+			continue
+		}
+
+		attrs = append(attrs, slog.String(tag.name, fmt.Sprint(fv.Interface())))
+	}
+
+	return attrs
+}
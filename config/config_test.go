@@ -0,0 +1,150 @@
+package config_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/config"
+	"maragu.dev/glue/model"
+)
+
+type testConfig struct {
+	Name     string             `env:"TEST_NAME" default:"Glue"`
+	Count    int                `env:"TEST_COUNT" default:"3"`
+	Enabled  bool               `env:"TEST_ENABLED"`
+	Timeout  time.Duration      `env:"TEST_TIMEOUT" default:"1s"`
+	Email    model.EmailAddress `env:"TEST_EMAIL"`
+	Tags     []string           `env:"TEST_TAGS"`
+	Password string             `env:"TEST_PASSWORD,secret"`
+	Nested   nestedConfig
+}
+
+type nestedConfig struct {
+	URL string `env:"TEST_URL,required"`
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("binds environment variables and defaults", func(t *testing.T) {
+		t.Setenv("TEST_COUNT", "5")
+		t.Setenv("TEST_ENABLED", "true")
+		t.Setenv("TEST_EMAIL", "me@example.com")
+		t.Setenv("TEST_TAGS", "a, b,c")
+		t.Setenv("TEST_URL", "https://example.com")
+
+		var c testConfig
+		err := config.Load(&c)
+		is.NotError(t, err)
+
+		is.Equal(t, "Glue", c.Name)
+		is.Equal(t, 5, c.Count)
+		is.True(t, c.Enabled)
+		is.Equal(t, time.Second, c.Timeout)
+		is.Equal(t, "me@example.com", c.Email)
+		is.EqualSlice(t, []string{"a", "b", "c"}, c.Tags)
+		is.Equal(t, "https://example.com", c.Nested.URL)
+	})
+
+	t.Run("loads .env files and ignores missing ones", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env")
+		err := os.WriteFile(path, []byte("TEST_URL=https://example.com/from-file\n"), 0o600)
+		is.NotError(t, err)
+		t.Cleanup(func() {
+			_ = os.Unsetenv("TEST_URL")
+		})
+
+		var c testConfig
+		err = config.Load(&c, filepath.Join(t.TempDir(), "doesnotexist"), path)
+		is.NotError(t, err)
+		is.Equal(t, "https://example.com/from-file", c.Nested.URL)
+	})
+
+	t.Run("reports all errors together", func(t *testing.T) {
+		t.Setenv("TEST_COUNT", "many")
+		t.Setenv("TEST_TIMEOUT", "forever")
+
+		var c testConfig
+		err := config.Load(&c)
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), `error parsing TEST_COUNT`))
+		is.True(t, strings.Contains(err.Error(), `error parsing TEST_TIMEOUT`))
+		is.True(t, strings.Contains(err.Error(), `TEST_URL is required`))
+	})
+
+	t.Run("calls Validate after binding", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "verbose")
+
+		var c config.App
+		err := config.Load(&c)
+		is.True(t, err != nil)
+		is.Equal(t, "LOG_LEVEL must be one of debug, info, warn, error, got verbose", err.Error())
+	})
+
+	t.Run("reports parse and validation errors together", func(t *testing.T) {
+		t.Setenv("LOG_JSON", "maybe")
+		t.Setenv("LOG_LEVEL", "verbose")
+
+		var c config.App
+		err := config.Load(&c)
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "error parsing LOG_JSON"))
+		is.True(t, strings.Contains(err.Error(), "LOG_LEVEL must be one of debug, info, warn, error, got verbose"))
+	})
+}
+
+func TestLogValue(t *testing.T) {
+	t.Run("keys fields by environment variable and redacts secrets", func(t *testing.T) {
+		c := testConfig{Name: "Glue", Password: "hunter2", Nested: nestedConfig{URL: "https://example.com"}}
+
+		var b strings.Builder
+		log := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == "time" {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		log.Info("Config", "config", config.LogValue(&c))
+
+		is.True(t, strings.Contains(b.String(), "config.TEST_NAME=Glue"))
+		is.True(t, strings.Contains(b.String(), "config.TEST_URL=https://example.com"))
+		is.True(t, strings.Contains(b.String(), "config.TEST_PASSWORD=[redacted]"))
+		is.True(t, !strings.Contains(b.String(), "hunter2"))
+	})
+}
+
+func TestReloadable(t *testing.T) {
+	t.Run("loads and replaces the config on reload", func(t *testing.T) {
+		t.Setenv("TEST_URL", "https://example.com")
+
+		var r config.Reloadable[testConfig]
+		err := r.Load()
+		is.NotError(t, err)
+		is.Equal(t, "https://example.com", r.Get().Nested.URL)
+
+		t.Setenv("TEST_URL", "https://example.com/reloaded")
+		err = r.Load()
+		is.NotError(t, err)
+		is.Equal(t, "https://example.com/reloaded", r.Get().Nested.URL)
+	})
+
+	t.Run("keeps the current config if reloading fails", func(t *testing.T) {
+		t.Setenv("TEST_URL", "https://example.com")
+
+		var r config.Reloadable[testConfig]
+		err := r.Load()
+		is.NotError(t, err)
+
+		t.Setenv("TEST_COUNT", "many")
+		err = r.Load()
+		is.True(t, err != nil)
+		is.Equal(t, "https://example.com", r.Get().Nested.URL)
+		is.Equal(t, 3, r.Get().Count)
+	})
+}
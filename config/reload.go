@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// Reloadable config of type T, which can be loaded again while in use, for example on SIGHUP.
+// Each [Reloadable.Load] loads into a new value, which replaces the current one only if loading succeeds,
+// so readers always get a complete and valid config from [Reloadable.Get].
+type Reloadable[T any] struct {
+	v atomic.Pointer[T]
+}
+
+// Get the current config. It's the zero value if the config has never been loaded successfully.
+func (r *Reloadable[T]) Get() T {
+	if v := r.v.Load(); v != nil {
+		return *v
+	}
+	var zero T
+	return zero
+}
+
+// Load the config with [Load], replacing the current config if successful.
+func (r *Reloadable[T]) Load(paths ...string) error {
+	v := new(T)
+	if err := Load(v, paths...); err != nil {
+		return err
+	}
+	r.v.Store(v)
+	return nil
+}
+
+// LogValue satisfies [slog.LogValuer], with the current config as given by [LogValue].
+func (r *Reloadable[T]) LogValue() slog.Value {
+	v := r.Get()
+	return LogValue(&v)
+}
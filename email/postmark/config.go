@@ -0,0 +1,58 @@
+package postmark
+
+import (
+	"io/fs"
+	"log/slog"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+// Config for [NewSender], to load with [maragu.dev/glue/config.Load].
+type Config struct {
+	AppName                   string             `env:"APP_NAME" default:"App"`
+	BaseURL                   string             `env:"BASE_URL" default:"http://localhost:8080"`
+	Key                       string             `env:"POSTMARK_KEY,required,secret"`
+	MarketingEmailAddress     model.EmailAddress `env:"MARKETING_EMAIL_ADDRESS,required"`
+	MarketingEmailName        string             `env:"MARKETING_EMAIL_NAME"`
+	ReplyToEmailAddress       model.EmailAddress `env:"REPLY_TO_EMAIL_ADDRESS,required"`
+	ReplyToEmailName          string             `env:"REPLY_TO_EMAIL_NAME"`
+	TransactionalEmailAddress model.EmailAddress `env:"TRANSACTIONAL_EMAIL_ADDRESS,required"`
+	TransactionalEmailName    string             `env:"TRANSACTIONAL_EMAIL_NAME"`
+}
+
+// Validate satisfies the validator interface of [maragu.dev/glue/config.Load].
+func (c *Config) Validate() error {
+	var errs []error
+	for _, a := range []struct {
+		name    string
+		address model.EmailAddress
+	}{
+		{"MARKETING_EMAIL_ADDRESS", c.MarketingEmailAddress},
+		{"REPLY_TO_EMAIL_ADDRESS", c.ReplyToEmailAddress},
+		{"TRANSACTIONAL_EMAIL_ADDRESS", c.TransactionalEmailAddress},
+	} {
+		if !a.address.IsValid() {
+			errs = append(errs, errors.Newf("%v must be a valid email address, got %v", a.name, a.address))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SenderOptions from the config.
+func (c Config) SenderOptions(log *slog.Logger, emails fs.FS) NewSenderOptions {
+	return NewSenderOptions{
+		AppName:                   c.AppName,
+		BaseURL:                   c.BaseURL,
+		Emails:                    emails,
+		Key:                       c.Key,
+		Log:                       log,
+		MarketingEmailAddress:     c.MarketingEmailAddress,
+		MarketingEmailName:        c.MarketingEmailName,
+		ReplyToEmailAddress:       c.ReplyToEmailAddress,
+		ReplyToEmailName:          c.ReplyToEmailName,
+		TransactionalEmailAddress: c.TransactionalEmailAddress,
+		TransactionalEmailName:    c.TransactionalEmailName,
+	}
+}
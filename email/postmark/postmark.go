@@ -10,17 +10,21 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
 	"go.opentelemetry.io/otel/trace"
 	"maragu.dev/errors"
 
 	"maragu.dev/glue/email"
+	"maragu.dev/glue/health"
 	"maragu.dev/glue/model"
 )
 
@@ -45,6 +49,7 @@ type Sender struct {
 	appName           string
 	baseURL           string
 	client            *http.Client
+	duration          metric.Float64Histogram
 	emails            fs.FS
 	endpointURL       string
 	key               string
@@ -60,6 +65,7 @@ type NewSenderOptions struct {
 	BaseURL                   string
 	EndpointURL               string
 	Emails                    fs.FS
+	Health                    *health.Registry
 	Key                       string
 	Log                       *slog.Logger
 	MarketingEmailAddress     model.EmailAddress
@@ -70,19 +76,32 @@ type NewSenderOptions struct {
 	TransactionalEmailName    string
 }
 
+// NewSender with the given options.
+// Operations are traced, and their durations recorded in the postmark.operation.duration histogram.
+// If the Health registry is given, [Sender.Ping] is registered in it as the "postmark" readiness check.
 func NewSender(opts NewSenderOptions) *Sender {
 	if opts.Log == nil {
 		opts.Log = slog.New(slog.DiscardHandler)
 	}
 
+	duration, err := otel.Meter("maragu.dev/glue/email/postmark").Float64Histogram("postmark.operation.duration",
+		metric.WithDescription("Duration of Postmark operations."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		opts.Log.Error("Error creating duration histogram, not recording durations", "error", err)
+		duration = noop.Float64Histogram{}
+	}
+
 	if opts.EndpointURL == "" {
 		opts.EndpointURL = "https://api.postmarkapp.com/email"
 	}
 
-	return &Sender{
+	s := &Sender{
 		appName:           strings.TrimSpace(opts.AppName),
 		baseURL:           strings.TrimSuffix(opts.BaseURL, "/"),
 		client:            &http.Client{Timeout: 3 * time.Second},
+		duration:          duration,
 		emails:            opts.Emails,
 		endpointURL:       strings.TrimSuffix(opts.EndpointURL, "/"),
 		key:               opts.Key,
@@ -92,6 +111,12 @@ func NewSender(opts NewSenderOptions) *Sender {
 		tracer:            otel.Tracer("maragu.dev/glue/email/postmark"),
 		transactionalFrom: createNameAndEmail(opts.TransactionalEmailName, opts.TransactionalEmailAddress),
 	}
+
+	if opts.Health != nil {
+		opts.Health.RegisterReady("postmark", s.Ping)
+	}
+
+	return s
 }
 
 func (s *Sender) SendTransactional(ctx context.Context, name string, email model.EmailAddress, subject, preheader, template string, kw model.Keywords) error {
@@ -126,6 +151,7 @@ func (s *Sender) send(ctx context.Context, typ emailType, name string, email mod
 		),
 	)
 	defer span.End()
+	defer s.recordDuration(ctx, "postmark.send", time.Now())
 
 	var messageStream string
 	var from nameAndEmail
@@ -254,6 +280,58 @@ func (s *Sender) sendRequest(ctx context.Context, body requestBody) error {
 	return nil
 }
 
+// Ping checks that Postmark is reachable and the server token is valid, using the "get the server" endpoint.
+// It can be used as a [maragu.dev/glue/health.Check].
+// See https://postmarkapp.com/developer/api/server-api#get-server
+func (s *Sender) Ping(ctx context.Context) error {
+	u, err := url.Parse(s.endpointURL)
+	if err != nil {
+		return errors.Wrap(err, "error parsing endpoint url")
+	}
+	u.Path = "/server"
+
+	ctx, span := s.operationTracerStart(ctx, "postmark.ping",
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodGet,
+			semconv.URLFull(u.String()),
+		),
+	)
+	defer span.End()
+	defer s.recordDuration(ctx, "postmark.ping", time.Now())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "request creation failed")
+		return errors.Wrap(err, "error creating request")
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Postmark-Server-Token", s.key)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "request failed")
+		return errors.Wrap(err, "error making request")
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+
+	if res.StatusCode >= 300 {
+		err := errors.Newf("error pinging postmark, got http status code %v", res.StatusCode)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "http error")
+		return err
+	}
+
+	return nil
+}
+
 // createNameAndEmail returns a name and email string ready for inserting into From and To fields.
 func createNameAndEmail(name string, email model.EmailAddress) nameAndEmail {
 	return fmt.Sprintf("%v <%v>", name, email.ToLower())
@@ -290,6 +368,11 @@ func getEmail(emails fs.FS, path, preheader string, keywords model.Keywords) str
 	return email
 }
 
+// recordDuration since start for the given operation.
+func (s *Sender) recordDuration(ctx context.Context, operation string, start time.Time) {
+	s.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attribute.String("postmark.operation", operation)))
+}
+
 func (s *Sender) operationTracerStart(ctx context.Context, operation string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	allOpts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
@@ -7,10 +7,13 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"maragu.dev/is"
 
 	"maragu.dev/glue/email/postmark"
+	"maragu.dev/glue/health"
 	"maragu.dev/glue/model"
+	"maragu.dev/glue/oteltest"
 )
 
 func TestSender_SendTransactional(t *testing.T) {
@@ -47,11 +50,90 @@ func TestSender_SendTransactional(t *testing.T) {
 		err := sender.SendTransactional(t.Context(), "You", "you@example.com", "Hi", "Hey there.", "generic", model.Keywords{})
 		is.NotError(t, err)
 	})
+
+	t.Run("records the send duration", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+
+		server, sender := newSender(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"ErrorCode":0}`))
+		})
+		defer server.Close()
+
+		err := sender.SendTransactional(t.Context(), "You", "you@example.com", "Hi", "Hey there.", "generic", model.Keywords{})
+		is.NotError(t, err)
+
+		m := oteltest.GetMetric(t, r, "postmark.operation.duration")
+		h, ok := m.Data.(metricdata.Histogram[float64])
+		is.True(t, ok)
+		is.Equal(t, 1, len(h.DataPoints))
+		operation, _ := h.DataPoints[0].Attributes.Value("postmark.operation")
+		is.Equal(t, "postmark.send", operation.AsString())
+	})
+}
+
+func TestNewSender(t *testing.T) {
+	t.Run("registers a readiness check that pings postmark", func(t *testing.T) {
+		var called bool
+		mux := chi.NewRouter()
+		mux.Get("/server", func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			_, _ = w.Write([]byte(`{"ID":1}`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		reg := health.NewRegistry(health.NewRegistryOptions{})
+		postmark.NewSender(postmark.NewSenderOptions{
+			EndpointURL: server.URL + "/email",
+			Health:      reg,
+			Key:         "123abc",
+		})
+
+		report := reg.Ready(t.Context())
+		is.Equal(t, health.StatusOK, report.Status)
+		is.Equal(t, "postmark", report.Checks[0].Name)
+		is.True(t, called)
+	})
+}
+
+func TestSender_Ping(t *testing.T) {
+	t.Run("returns no error when the server token is accepted", func(t *testing.T) {
+		var token string
+		server, sender := newSenderWithMux(func(mux chi.Router) {
+			mux.Get("/server", func(w http.ResponseWriter, r *http.Request) {
+				token = r.Header.Get("X-Postmark-Server-Token")
+				_, _ = w.Write([]byte(`{"ID":1}`))
+			})
+		})
+		defer server.Close()
+
+		err := sender.Ping(t.Context())
+		is.NotError(t, err)
+		is.Equal(t, "123abc", token)
+	})
+
+	t.Run("returns error on 300+ HTTP status code from API", func(t *testing.T) {
+		server, sender := newSenderWithMux(func(mux chi.Router) {
+			mux.Get("/server", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+		})
+		defer server.Close()
+
+		err := sender.Ping(t.Context())
+		is.Equal(t, "error pinging postmark, got http status code 401", err.Error())
+	})
 }
 
 func newSender(h http.HandlerFunc) (*httptest.Server, *postmark.Sender) {
+	return newSenderWithMux(func(mux chi.Router) {
+		mux.Post("/email", h)
+	})
+}
+
+func newSenderWithMux(routes func(mux chi.Router)) (*httptest.Server, *postmark.Sender) {
 	mux := chi.NewRouter()
-	mux.Post("/email", h)
+	routes(mux)
 	server := httptest.NewServer(mux)
 	sender := postmark.NewSender(postmark.NewSenderOptions{
 		BaseURL:                   "http://localhost:1234",
@@ -0,0 +1,192 @@
+// Package health provides a registry of liveness and readiness checks for app components.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether a component is healthy, by returning nil.
+type Check = func(ctx context.Context) error
+
+type Status string
+
+const (
+	StatusOK           = Status("ok")
+	StatusError        = Status("error")
+	StatusShuttingDown = Status("shutting down")
+)
+
+// Registry of liveness and readiness checks.
+// Components register checks with [Registry.RegisterLive] and [Registry.RegisterReady],
+// and [Registry.Shutdown] makes readiness fail, so load balancers can drain traffic before shutdown.
+type Registry struct {
+	liveChecks   []namedCheck
+	lock         sync.RWMutex
+	readyChecks  []namedCheck
+	shuttingDown atomic.Bool
+	timeout      time.Duration
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type NewRegistryOptions struct {
+	// Timeout for each check. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+func NewRegistry(opts NewRegistryOptions) *Registry {
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	return &Registry{
+		timeout: opts.Timeout,
+	}
+}
+
+// RegisterLive registers a liveness check under name, replacing any check already registered under it.
+// Liveness checks should only fail if the process needs to be restarted.
+func (r *Registry) RegisterLive(name string, check Check) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.liveChecks = register(r.liveChecks, name, check)
+}
+
+// RegisterReady registers a readiness check under name, replacing any check already registered under it.
+// Readiness checks should fail if the app cannot currently serve traffic, for example because the database is down.
+func (r *Registry) RegisterReady(name string, check Check) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.readyChecks = register(r.readyChecks, name, check)
+}
+
+// register the check under name in a copy of checks, so checks being run concurrently aren't changed.
+func register(checks []namedCheck, name string, check Check) []namedCheck {
+	checks = slices.Clone(checks)
+	for i := range checks {
+		if checks[i].name == name {
+			checks[i].check = check
+			return checks
+		}
+	}
+	return append(checks, namedCheck{name: name, check: check})
+}
+
+// Shutdown marks the app as shutting down, which makes readiness fail from now on.
+// It is safe to call multiple times.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// IsShuttingDown reports whether [Registry.Shutdown] has been called.
+func (r *Registry) IsShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+// Report of running a set of checks.
+type Report struct {
+	Status Status        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult of running a single check.
+type CheckResult struct {
+	Name       string  `json:"name"`
+	Status     Status  `json:"status"`
+	DurationMS float64 `json:"durationMs"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Live runs the liveness checks.
+func (r *Registry) Live(ctx context.Context) Report {
+	r.lock.RLock()
+	checks := r.liveChecks
+	r.lock.RUnlock()
+
+	return r.run(ctx, checks)
+}
+
+// Ready runs the readiness checks, and reports [StatusShuttingDown] without running them if shutting down.
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.IsShuttingDown() {
+		return Report{Status: StatusShuttingDown, Checks: []CheckResult{}}
+	}
+
+	r.lock.RLock()
+	checks := r.readyChecks
+	r.lock.RUnlock()
+
+	return r.run(ctx, checks)
+}
+
+// run the checks concurrently, each with the registry timeout.
+func (r *Registry) run(ctx context.Context, checks []namedCheck) Report {
+	report := Report{Status: StatusOK, Checks: make([]CheckResult, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(ctx)
+			result := CheckResult{
+				Name:       c.name,
+				Status:     StatusOK,
+				DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusError
+				result.Error = err.Error()
+			}
+			report.Checks[i] = result
+		})
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusError
+			break
+		}
+	}
+
+	return report
+}
+
+// LiveHandler responds with the liveness [Report] as JSON, with status 200 if all checks pass and 503 otherwise.
+func (r *Registry) LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Live(req.Context()))
+	}
+}
+
+// ReadyHandler responds with the readiness [Report] as JSON, with status 200 if all checks pass and 503 otherwise.
+func (r *Registry) ReadyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Ready(req.Context()))
+	}
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if report.Status == StatusOK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,130 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/health"
+)
+
+func TestRegistry_Ready(t *testing.T) {
+	t.Run("reports ok when all checks pass", func(t *testing.T) {
+		r := health.NewRegistry(health.NewRegistryOptions{})
+		r.RegisterReady("db", func(ctx context.Context) error { return nil })
+		r.RegisterReady("s3", func(ctx context.Context) error { return nil })
+
+		report := r.Ready(t.Context())
+		is.Equal(t, health.StatusOK, report.Status)
+		is.Equal(t, 2, len(report.Checks))
+		is.Equal(t, "db", report.Checks[0].Name)
+		is.Equal(t, health.StatusOK, report.Checks[0].Status)
+		is.Equal(t, "s3", report.Checks[1].Name)
+	})
+
+	t.Run("reports error when a check fails", func(t *testing.T) {
+		r := health.NewRegistry(health.NewRegistryOptions{})
+		r.RegisterReady("db", func(ctx context.Context) error { return nil })
+		r.RegisterReady("s3", func(ctx context.Context) error { return errors.New("oh no") })
+
+		report := r.Ready(t.Context())
+		is.Equal(t, health.StatusError, report.Status)
+		is.Equal(t, health.StatusOK, report.Checks[0].Status)
+		is.Equal(t, health.StatusError, report.Checks[1].Status)
+		is.Equal(t, "oh no", report.Checks[1].Error)
+	})
+
+	t.Run("replaces a check registered under the same name", func(t *testing.T) {
+		r := health.NewRegistry(health.NewRegistryOptions{})
+		r.RegisterReady("db", func(ctx context.Context) error { return errors.New("oh no") })
+		r.RegisterReady("s3", func(ctx context.Context) error { return nil })
+		r.RegisterReady("db", func(ctx context.Context) error { return nil })
+
+		report := r.Ready(t.Context())
+		is.Equal(t, health.StatusOK, report.Status)
+		is.Equal(t, 2, len(report.Checks))
+		is.Equal(t, "db", report.Checks[0].Name)
+		is.Equal(t, "s3", report.Checks[1].Name)
+	})
+
+	t.Run("times out slow checks", func(t *testing.T) {
+		r := health.NewRegistry(health.NewRegistryOptions{Timeout: time.Millisecond})
+		r.RegisterReady("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		report := r.Ready(t.Context())
+		is.Equal(t, health.StatusError, report.Status)
+		is.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	})
+
+	t.Run("reports shutting down without running checks after shutdown", func(t *testing.T) {
+		r := health.NewRegistry(health.NewRegistryOptions{})
+		var called bool
+		r.RegisterReady("db", func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+
+		r.Shutdown()
+
+		report := r.Ready(t.Context())
+		is.Equal(t, health.StatusShuttingDown, report.Status)
+		is.True(t, !called)
+		is.True(t, r.IsShuttingDown())
+	})
+}
+
+func TestRegistry_Live(t *testing.T) {
+	t.Run("keeps running liveness checks after shutdown", func(t *testing.T) {
+		r := health.NewRegistry(health.NewRegistryOptions{})
+		r.RegisterLive("goroutines", func(ctx context.Context) error { return nil })
+		r.Shutdown()
+
+		report := r.Live(t.Context())
+		is.Equal(t, health.StatusOK, report.Status)
+		is.Equal(t, 1, len(report.Checks))
+	})
+}
+
+func TestRegistry_ReadyHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		shutdown     bool
+		expectStatus int
+		expectBody   health.Status
+	}{
+		{name: "passing", expectStatus: http.StatusOK, expectBody: health.StatusOK},
+		{name: "failing", err: errors.New("oh no"), expectStatus: http.StatusServiceUnavailable, expectBody: health.StatusError},
+		{name: "shutting down", shutdown: true, expectStatus: http.StatusServiceUnavailable, expectBody: health.StatusShuttingDown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := health.NewRegistry(health.NewRegistryOptions{})
+			r.RegisterReady("db", func(ctx context.Context) error { return test.err })
+			if test.shutdown {
+				r.Shutdown()
+			}
+
+			rec := httptest.NewRecorder()
+			r.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			is.Equal(t, test.expectStatus, rec.Code)
+			is.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var report health.Report
+			err := json.Unmarshal(rec.Body.Bytes(), &report)
+			is.NotError(t, err)
+			is.Equal(t, test.expectBody, report.Status)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package html
+
+import (
+	"net/url"
+	"slices"
+
+	. "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+
+	"maragu.dev/glue/model"
+)
+
+// LoginPage with a form to request a login link by email. redirect is where to go after logging in, if not empty.
+func LoginPage(page PageFunc, redirect string) Node {
+	return page(PageProps{Title: "Log in", HideAuth: true},
+		H1(Text("Log in")),
+
+		Form(Method("post"), Action("/login"),
+			If(redirect != "", Input(Type("hidden"), Name("redirect"), Value(redirect))),
+
+			Label(For("email"), Text("Email")),
+			Input(Type("email"), ID("email"), Name("email"), AutoComplete("email"), Required()),
+
+			Button(Type("submit"), Text("Send login link")),
+		),
+
+		P(Text("No account yet? "), A(Href("/signup"), Text("Sign up"))),
+	)
+}
+
+// SignupPage with a form to sign up with a name and email.
+func SignupPage(page PageFunc) Node {
+	return page(PageProps{Title: "Sign up", HideAuth: true},
+		H1(Text("Sign up")),
+
+		Form(Method("post"), Action("/signup"),
+			Label(For("name"), Text("Name")),
+			Input(Type("text"), ID("name"), Name("name"), AutoComplete("name"), Required()),
+
+			Label(For("email"), Text("Email")),
+			Input(Type("email"), ID("email"), Name("email"), AutoComplete("email"), Required()),
+
+			Button(Type("submit"), Text("Sign up")),
+		),
+
+		P(Text("Already have an account? "), A(Href("/login"), Text("Log in"))),
+	)
+}
+
+// CheckEmailPage tells the user that a link has been sent to the given email address.
+// It's shown whether or not an email was actually sent, so it doesn't reveal which addresses have accounts.
+func CheckEmailPage(page PageFunc, email model.EmailAddress) Node {
+	return page(PageProps{Title: "Check your email", HideAuth: true},
+		H1(Text("Check your email")),
+		P(Text("If "), Strong(Text(email.String())), Text(" has an account, we've sent a login link to it. The link expires soon, and can only be used once.")),
+	)
+}
+
+// InvalidLinkPage for links with tokens that don't exist, have been used, or have expired.
+func InvalidLinkPage(page PageFunc) Node {
+	return page(PageProps{Title: "Invalid link", HideAuth: true},
+		H1(Text("Invalid link")),
+		P(Text("This link is invalid, has expired, or has already been used. "), A(Href("/login"), Text("Get a new one")), Text(".")),
+	)
+}
+
+// ChangeEmailPage with a form to change the email address from the current one.
+func ChangeEmailPage(page PageFunc, current model.EmailAddress) Node {
+	return page(PageProps{Title: "Change email"},
+		H1(Text("Change email")),
+
+		P(Text("Your current email address is "), Strong(Text(current.String())), Text(".")),
+
+		Form(Method("post"), Action("/profile/email"),
+			Label(For("email"), Text("New email")),
+			Input(Type("email"), ID("email"), Name("email"), AutoComplete("email"), Required()),
+
+			Button(Type("submit"), Text("Change email")),
+		),
+	)
+}
+
+// ConfirmEmailChangePage tells the user that a confirmation link has been sent to the new email address.
+func ConfirmEmailChangePage(page PageFunc, email model.EmailAddress) Node {
+	return page(PageProps{Title: "Confirm your new email"},
+		H1(Text("Confirm your new email")),
+		P(Text("We've sent a link to "), Strong(Text(email.String())), Text(". Click it to confirm the change.")),
+	)
+}
+
+// EmailChangedPage tells the user that the email address has been changed.
+func EmailChangedPage(page PageFunc, email model.EmailAddress) Node {
+	return page(PageProps{Title: "Email changed"},
+		H1(Text("Email changed")),
+		P(Text("You now log in with "), Strong(Text(email.String())), Text(".")),
+	)
+}
+
+// EmailConflictPage tells the user that the new email address already belongs to another user.
+func EmailConflictPage(page PageFunc) Node {
+	return page(PageProps{Title: "Email already in use"},
+		H1(Text("Email already in use")),
+		P(Text("Another account already uses that email address. "), A(Href("/profile/email"), Text("Try another one")), Text(".")),
+	)
+}
+
+// UserRolesPage lists all roles for the user with the given ID, with forms to grant the roles the user doesn't have,
+// and revoke the ones the user has.
+func UserRolesPage(page PageFunc, userID model.UserID, roles, granted []model.Role) Node {
+	base := "/admin/users/" + url.PathEscape(userID.String()) + "/roles"
+
+	return page(PageProps{Title: "Roles"},
+		H1(Text("Roles")),
+
+		P(Text("Roles of user "), Code(Text(userID.String())), Text(".")),
+
+		Ul(
+			Map(roles, func(r model.Role) Node {
+				has := slices.Contains(granted, r)
+				action, label := "grant", "Grant"
+				if has {
+					action, label = "revoke", "Revoke"
+				}
+
+				return Li(
+					Form(Method("post"), Action(base+"/"+action),
+						Input(Type("hidden"), Name("role"), Value(r.String())),
+						If(has, Strong(Text(r.Pretty()))),
+						If(!has, Text(r.Pretty())),
+						Text(" "),
+						Button(Type("submit"), Text(label)),
+					),
+				)
+			}),
+		),
+	)
+}
+
+// SessionsPage lists the user's active sessions, with forms to revoke each of them or all at once.
+// The current session is marked.
+func SessionsPage(page PageFunc, sessions []model.Session, current model.SessionID) Node {
+	return page(PageProps{Title: "Sessions"},
+		H1(Text("Sessions")),
+
+		P(Text("These are the devices where you're logged in.")),
+
+		Ul(
+			Map(sessions, func(s model.Session) Node {
+				return Li(
+					Form(Method("post"), Action("/profile/sessions/"+url.PathEscape(s.ID.String())+"/revoke"),
+						Strong(Text(getSessionDescription(s))),
+						If(s.ID == current, Text(" (this device)")),
+						Br(),
+						Text(s.IP+", last seen "+s.LastSeen.T.UTC().Format("2006-01-02 15:04")+" UTC"),
+						Text(" "),
+						Button(Type("submit"), Text("Log out")),
+					),
+				)
+			}),
+		),
+
+		Form(Method("post"), Action("/profile/sessions/revoke"),
+			Button(Type("submit"), Text("Log out everywhere")),
+		),
+	)
+}
+
+func getSessionDescription(s model.Session) string {
+	switch {
+	case s.Browser != "" && s.OS != "":
+		return s.Browser + " on " + s.OS
+	case s.Browser != "":
+		return s.Browser
+	case s.OS != "":
+		return s.OS
+	default:
+		return "Unknown device"
+	}
+}
@@ -2,6 +2,7 @@ package html
 
 import (
 	"context"
+	"crypto/rand"
 	"net/http"
 	"slices"
 
@@ -21,6 +22,29 @@ type PageProps struct {
 	HideAuth    bool
 	UserID      *model.UserID
 	Permissions []model.Permission
+	// Impersonating is true if UserID is a user being impersonated by an admin, for example to show a banner.
+	Impersonating bool
+	// HTMX request details, see [PageOrFragment].
+	HTMX HTMX
+}
+
+// HTMX request details from the HX-* request headers sent by htmx.
+type HTMX struct {
+	// Request is true for all requests made by htmx.
+	Request bool
+	// Boosted is true for requests from elements with hx-boost, which expect the full page.
+	Boosted bool
+	// Target is the ID of the target element, if it has one.
+	Target string
+	// Trigger is the ID of the triggered element, if it has one.
+	Trigger string
+	// CurrentURL of the browser.
+	CurrentURL string
+}
+
+// Fragment is true for htmx requests that swap in part of a page, so the response doesn't need the page layout.
+func (h HTMX) Fragment() bool {
+	return h.Request && !h.Boosted
 }
 
 func (p PageProps) HasPermission(perm model.Permission) bool {
@@ -29,6 +53,19 @@ func (p PageProps) HasPermission(perm model.Permission) bool {
 
 type PageFunc = func(props PageProps, children ...Node) Node
 
+// PageOrFragment renders the children in the page for regular requests, and just the children for htmx fragment requests,
+// see [HTMX.Fragment]. The response varies by the HX-Request header, so caches keep the two apart.
+func PageOrFragment(page PageFunc, props PageProps, children ...Node) Node {
+	if props.W != nil {
+		props.W.Header().Add("Vary", "HX-Request")
+	}
+
+	if props.HTMX.Fragment() {
+		return Group(children)
+	}
+	return page(props, children...)
+}
+
 func FavIcons(name string) Node {
 	return Group{
 		// <link rel="icon" type="image/png" href="/favicon-96x96.png" sizes="96x96" />
@@ -51,6 +88,15 @@ func FavIcons(name string) Node {
 	}
 }
 
+// IdempotencyKeyField is the name of the hidden form field with an idempotency key, see [IdempotencyKeyInput].
+const IdempotencyKeyField = "idempotency_key"
+
+// IdempotencyKeyInput is a hidden form field with a new random idempotency key, so submitting the form twice
+// replays the first response instead of repeating the action, when using the idempotency middleware from the http package.
+func IdempotencyKeyInput() Node {
+	return Input(Type("hidden"), Name(IdempotencyKeyField), Value(rand.Text()))
+}
+
 func Container(padX, padY bool, children ...Node) Node {
 	return Div(
 		Classes{
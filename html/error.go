@@ -16,3 +16,25 @@ func NotFoundPage(page PageFunc) Node {
 		H1(Text("Not found")),
 	)
 }
+
+func ForbiddenPage(page PageFunc) Node {
+	return page(PageProps{Title: "Forbidden"},
+		H1(Text("Forbidden")),
+		P(Text("You don't have permission to see this page.")),
+	)
+}
+
+func TooManyRequestsPage(page PageFunc) Node {
+	return page(PageProps{Title: "Too many requests"},
+		H1(Text("Too many requests")),
+		P(Text("Please wait a moment and try again.")),
+	)
+}
+
+// ProblemPage for errors without a more specific page, with the title and an optional detail.
+func ProblemPage(page PageFunc, title, detail string) Node {
+	return page(PageProps{Title: title},
+		H1(Text(title)),
+		If(detail != "", P(Text(detail))),
+	)
+}
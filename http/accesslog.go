@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"maragu.dev/glue/model"
+)
+
+const contextAccessLogEntryKey = ContextKey("accessLogEntry")
+
+// accessLogEntry is stored in the request context by [AccessLog], so later middleware can add to it, see [withUserID].
+type accessLogEntry struct {
+	userID *model.UserID
+}
+
+type AccessLogOptions struct {
+	// ExcludePathPrefixes are not logged. Defaults to the static asset directories served by [Static], and the health checks.
+	// Set it to an empty slice to log all paths.
+	ExcludePathPrefixes []string
+
+	Log *slog.Logger
+
+	// Sample requests with SampleRate instead of logging all of them.
+	Sample bool
+
+	// SampleRate between 0 and 1 is the fraction of requests to log if Sample is true.
+	// Requests with a 5xx status are always logged, so a SampleRate of 0 logs only server errors.
+	SampleRate float64
+}
+
+// AccessLog is [Middleware] that logs one line per request, with the method, route pattern, status, duration,
+// response size, user ID, and whether the client disconnected before the response.
+// Use it after [OpenTelemetry], so the line has the trace and span IDs when using [maragu.dev/glue/log.NewLogger].
+func AccessLog(opts AccessLogOptions) Middleware {
+	if opts.ExcludePathPrefixes == nil {
+		opts.ExcludePathPrefixes = []string{"/images/", "/scripts/", "/styles/", "/favicon", "/health/"}
+	}
+
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	log := opts.Log
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range opts.ExcludePathPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			entry := &accessLogEntry{}
+			ctx := context.WithValue(r.Context(), contextAccessLogEntryKey, entry)
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if opts.Sample && status < http.StatusInternalServerError && rand.Float64() >= opts.SampleRate {
+				return
+			}
+
+			var routePattern string
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				routePattern = rctx.RoutePattern()
+			}
+
+			var userID string
+			if entry.userID != nil {
+				userID = entry.userID.String()
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			log.LogAttrs(ctx, level, "Request",
+				slog.String("method", r.Method),
+				slog.String("route", routePattern),
+				slog.Int("status", status),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.String("userID", userID),
+				slog.Bool("clientDisconnected", contextCanceled(ctx.Err())),
+			)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+)
+
+func TestAccessLog(t *testing.T) {
+	newMux := func(opts gluehttp.AccessLogOptions) (*chi.Mux, *bytes.Buffer) {
+		var buf bytes.Buffer
+		opts.Log = slog.New(slog.NewJSONHandler(&buf, nil))
+
+		mux := chi.NewMux()
+		mux.Use(gluehttp.AccessLog(opts))
+		mux.Group(func(r chi.Router) {
+			r.Use(gluehttp.Authenticate(slog.New(slog.DiscardHandler), &mockSessionManager{exists: true}, &mockUserActiveChecker{active: true}))
+			r.Get("/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("thing"))
+			})
+		})
+		mux.Get("/error", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		mux.Get("/images/logo.png", func(w http.ResponseWriter, r *http.Request) {})
+		return mux, &buf
+	}
+
+	t.Run("logs one line per request with the route, status, size, and user ID", func(t *testing.T) {
+		mux, buf := newMux(gluehttp.AccessLogOptions{})
+
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/things/42", nil))
+
+		var line struct {
+			Level              string
+			Msg                string
+			Method             string
+			Route              string
+			Status             int
+			Bytes              int
+			UserID             string
+			ClientDisconnected bool
+			Duration           int64
+		}
+		is.NotError(t, json.Unmarshal(buf.Bytes(), &line))
+		is.Equal(t, "INFO", line.Level)
+		is.Equal(t, "Request", line.Msg)
+		is.Equal(t, "GET", line.Method)
+		is.Equal(t, "/things/{id}", line.Route)
+		is.Equal(t, http.StatusOK, line.Status)
+		is.Equal(t, 5, line.Bytes)
+		is.Equal(t, "u_123", line.UserID)
+		is.True(t, !line.ClientDisconnected)
+		is.True(t, line.Duration > 0)
+	})
+
+	t.Run("does not log excluded paths", func(t *testing.T) {
+		mux, buf := newMux(gluehttp.AccessLogOptions{})
+
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/images/logo.png", nil))
+
+		is.Equal(t, 0, buf.Len())
+	})
+
+	t.Run("samples requests, but always logs server errors", func(t *testing.T) {
+		mux, buf := newMux(gluehttp.AccessLogOptions{Sample: true, SampleRate: 0})
+
+		for range 10 {
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/things/42", nil))
+		}
+		is.Equal(t, 0, buf.Len())
+
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", nil))
+		is.Equal(t, 1, strings.Count(buf.String(), "\n"))
+		is.True(t, strings.Contains(buf.String(), `"level":"ERROR"`))
+	})
+}
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+type accessTokenStore interface {
+	CreateAccessToken(ctx context.Context, userID model.UserID, name string, scopes []model.Permission, lifetime time.Duration) (model.AccessToken, string, error)
+	GetAccessTokens(ctx context.Context, userID model.UserID) ([]model.AccessToken, error)
+	// RevokeAccessToken returns [model.ErrorTokenNotFound] if the user has no token with the ID.
+	RevokeAccessToken(ctx context.Context, userID model.UserID, id model.AccessTokenID) error
+}
+
+type AccessTokensOptions struct {
+	Log *slog.Logger
+
+	// PermissionsGetter is used to check that new tokens only get scopes the user has permissions for.
+	// If nil, any scopes are allowed, which is fine as long as [Authorize] also checks permissions.
+	PermissionsGetter permissionsGetter
+
+	// Store for access tokens, such as [maragu.dev/glue/sql.Helper].
+	Store accessTokenStore
+}
+
+type accessTokenResponse struct {
+	ID       model.AccessTokenID `json:"id"`
+	Name     string              `json:"name"`
+	Scopes   []model.Permission  `json:"scopes"`
+	Created  model.Time          `json:"created"`
+	LastUsed *model.Time         `json:"lastUsed"`
+	Expires  *model.Time         `json:"expires"`
+}
+
+func newAccessTokenResponse(at model.AccessToken) accessTokenResponse {
+	scopes := at.Scopes
+	if scopes == nil {
+		scopes = []model.Permission{}
+	}
+
+	return accessTokenResponse{
+		ID:       at.ID,
+		Name:     at.Name,
+		Scopes:   scopes,
+		Created:  at.Created,
+		LastUsed: at.LastUsed,
+		Expires:  at.Expires,
+	}
+}
+
+type listAccessTokensResponse struct {
+	Tokens []accessTokenResponse `json:"tokens"`
+}
+
+type createAccessTokenRequest struct {
+	Name          string             `json:"name"`
+	Scopes        []model.Permission `json:"scopes"`
+	ExpiresInDays int                `json:"expiresInDays"`
+}
+
+// Validate satisfies [validator].
+func (r createAccessTokenRequest) Validate() error {
+	if r.Name == "" || len(r.Name) > 100 {
+		return errors.New("name must be between 1 and 100 characters")
+	}
+	if r.ExpiresInDays < 0 || r.ExpiresInDays > 3650 {
+		return errors.New("expiresInDays must be between 0 and 3650")
+	}
+	return nil
+}
+
+type createAccessTokenResponse struct {
+	accessTokenResponse
+	Token string `json:"token"`
+}
+
+// StatusCode satisfies [statusCodeGiver].
+func (createAccessTokenResponse) StatusCode() int {
+	return http.StatusCreated
+}
+
+type noContentResponse struct{}
+
+// StatusCode satisfies [statusCodeGiver].
+func (noContentResponse) StatusCode() int {
+	return http.StatusNoContent
+}
+
+// AccessTokens registers JSON API routes for users to manage their personal access tokens, used with [AuthenticateBearer]:
+//   - GET /api/tokens lists the user's tokens.
+//   - POST /api/tokens creates a token from a body like {"name": "CLI", "scopes": ["read"], "expiresInDays": 30},
+//     and responds with the token string, which is only shown this once. Tokens without expiresInDays don't expire, and it can be at most 3650.
+//   - DELETE /api/tokens/{id} revokes a token.
+//
+// Users must be logged in with a session. Requests authenticated with an access token get 403 Forbidden,
+// so a token can't be used to create tokens with other scopes.
+func AccessTokens(r *Router, opts AccessTokensOptions) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	log := opts.Log
+
+	GetJSON(r, "/api/tokens", func(ctx context.Context, props html.PageProps, req struct{}) (listAccessTokensResponse, error) {
+		userID, err := getSessionUserID(ctx)
+		if err != nil {
+			return listAccessTokensResponse{}, err
+		}
+
+		ats, err := opts.Store.GetAccessTokens(ctx, userID)
+		if err != nil {
+			return listAccessTokensResponse{}, err
+		}
+
+		res := listAccessTokensResponse{Tokens: []accessTokenResponse{}}
+		for _, at := range ats {
+			res.Tokens = append(res.Tokens, newAccessTokenResponse(at))
+		}
+		return res, nil
+	})
+
+	PostJSON(r, "/api/tokens", func(ctx context.Context, props html.PageProps, req createAccessTokenRequest) (createAccessTokenResponse, error) {
+		userID, err := getSessionUserID(ctx)
+		if err != nil {
+			return createAccessTokenResponse{}, err
+		}
+
+		if opts.PermissionsGetter != nil {
+			permissions, err := opts.PermissionsGetter.GetPermissions(ctx, userID)
+			if err != nil {
+				return createAccessTokenResponse{}, err
+			}
+			for _, scope := range req.Scopes {
+				if !slices.Contains(permissions, scope) {
+					return createAccessTokenResponse{}, Error{Code: http.StatusForbidden, Err: errors.New("no permission for scope " + string(scope))}
+				}
+			}
+		}
+
+		lifetime := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+		at, token, err := opts.Store.CreateAccessToken(ctx, userID, req.Name, req.Scopes, lifetime)
+		if err != nil {
+			return createAccessTokenResponse{}, err
+		}
+
+		log.InfoContext(ctx, "Created access token", "userID", userID, "tokenID", at.ID)
+		return createAccessTokenResponse{accessTokenResponse: newAccessTokenResponse(at), Token: token}, nil
+	})
+
+	DeleteJSON(r, "/api/tokens/{id}", func(ctx context.Context, props html.PageProps, req struct{}) (noContentResponse, error) {
+		userID, err := getSessionUserID(ctx)
+		if err != nil {
+			return noContentResponse{}, err
+		}
+
+		id := model.AccessTokenID(GetPathParam(props.R, "id"))
+		if err := opts.Store.RevokeAccessToken(ctx, userID, id); err != nil {
+			return noContentResponse{}, err
+		}
+
+		log.InfoContext(ctx, "Revoked access token", "userID", userID, "tokenID", id)
+		return noContentResponse{}, nil
+	})
+}
+
+// getSessionUserID from the context, returning an [Error] if the user isn't logged in with a session,
+// or is impersonated by an admin, see [Impersonate].
+func getSessionUserID(ctx context.Context) (model.UserID, error) {
+	userID := GetUserIDFromContext(ctx)
+	if userID == nil {
+		return "", Error{Code: http.StatusUnauthorized}
+	}
+	if GetAccessTokenFromContext(ctx) != nil {
+		return "", Error{Code: http.StatusForbidden, Err: errors.New("access tokens can't be managed with an access token")}
+	}
+	if IsImpersonating(ctx) {
+		return "", Error{Code: http.StatusForbidden, Err: errors.New("can't be managed while impersonating")}
+	}
+	return *userID, nil
+}
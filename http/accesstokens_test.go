@@ -0,0 +1,273 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type mockAccessTokenStore struct {
+	tokens map[string]model.AccessToken
+	err    error
+}
+
+func (m *mockAccessTokenStore) AuthenticateAccessToken(ctx context.Context, token string) (model.AccessToken, error) {
+	if m.err != nil {
+		return model.AccessToken{}, m.err
+	}
+	at, ok := m.tokens[token]
+	if !ok {
+		return model.AccessToken{}, model.ErrorTokenNotFound
+	}
+	return at, nil
+}
+
+func (m *mockAccessTokenStore) CreateAccessToken(ctx context.Context, userID model.UserID, name string, scopes []model.Permission, lifetime time.Duration) (model.AccessToken, string, error) {
+	at := model.AccessToken{ID: model.AccessTokenID("at_" + name), UserID: userID, Name: name, Scopes: scopes}
+	token := "glue_pat_" + name
+	m.tokens[token] = at
+	return at, token, nil
+}
+
+func (m *mockAccessTokenStore) GetAccessTokens(ctx context.Context, userID model.UserID) ([]model.AccessToken, error) {
+	var ats []model.AccessToken
+	for _, at := range m.tokens {
+		if at.UserID == userID {
+			ats = append(ats, at)
+		}
+	}
+	return ats, nil
+}
+
+func (m *mockAccessTokenStore) RevokeAccessToken(ctx context.Context, userID model.UserID, id model.AccessTokenID) error {
+	for token, at := range m.tokens {
+		if at.ID == id && at.UserID == userID {
+			delete(m.tokens, token)
+			return nil
+		}
+	}
+	return model.ErrorTokenNotFound
+}
+
+func TestAuthenticateBearer(t *testing.T) {
+	tests := []struct {
+		name                    string
+		header                  string
+		userActive              bool
+		storeErr                error
+		expectStatus            int
+		expectNextHandlerCalled bool
+		expectUserIDInContext   bool
+	}{
+		{
+			name:                    "no authorization header",
+			expectStatus:            http.StatusOK,
+			expectNextHandlerCalled: true,
+		},
+		{
+			name:                    "valid token",
+			header:                  "Bearer glue_pat_valid",
+			userActive:              true,
+			expectStatus:            http.StatusOK,
+			expectNextHandlerCalled: true,
+			expectUserIDInContext:   true,
+		},
+		{
+			name:         "unknown token",
+			header:       "Bearer glue_pat_unknown",
+			userActive:   true,
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "expired token",
+			header:       "Bearer glue_pat_valid",
+			userActive:   true,
+			storeErr:     model.ErrorTokenExpired,
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "not a bearer token",
+			header:       "Basic dXNlcjpwYXNz",
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "inactive user",
+			header:       "Bearer glue_pat_valid",
+			userActive:   false,
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "error authenticating token",
+			header:       "Bearer glue_pat_valid",
+			storeErr:     errors.New("oh no"),
+			expectStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &mockAccessTokenStore{
+				tokens: map[string]model.AccessToken{"glue_pat_valid": {ID: "at_1", UserID: "u_123"}},
+				err:    test.storeErr,
+			}
+			authenticate := gluehttp.AuthenticateBearer(slog.New(slog.DiscardHandler), store, &mockUserActiveChecker{active: test.userActive})
+
+			var called bool
+			var userID *model.UserID
+			var at *model.AccessToken
+			h := authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				userID = gluehttp.GetUserIDFromContext(r.Context())
+				at = gluehttp.GetAccessTokenFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			is.Equal(t, test.expectStatus, rec.Code)
+			is.Equal(t, test.expectNextHandlerCalled, called)
+			if test.expectStatus == http.StatusUnauthorized {
+				is.Equal(t, `Bearer error="invalid_token"`, rec.Header().Get("WWW-Authenticate"))
+			}
+			if test.expectUserIDInContext {
+				is.True(t, userID != nil)
+				is.Equal(t, model.UserID("u_123"), *userID)
+				is.True(t, at != nil)
+				is.Equal(t, model.AccessTokenID("at_1"), at.ID)
+			} else {
+				is.True(t, userID == nil)
+			}
+		})
+	}
+}
+
+func TestAuthorize_accessTokenScopes(t *testing.T) {
+	store := &mockAccessTokenStore{tokens: map[string]model.AccessToken{
+		"glue_pat_read": {ID: "at_1", UserID: "u_123", Scopes: []model.Permission{"read"}},
+	}}
+	authenticate := gluehttp.AuthenticateBearer(slog.New(slog.DiscardHandler), store, &mockUserActiveChecker{active: true})
+	pg := &mockPermissionsGetter{permissions: []model.Permission{"read", "write"}}
+
+	t.Run("allows permissions in the token scopes", func(t *testing.T) {
+		h := authenticate(gluehttp.Authorize(slog.New(slog.DiscardHandler), pg, "read")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+		rec := serveBearer(h, http.MethodGet, "/", "glue_pat_read", "")
+		is.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("forbids permissions the user has but the token scopes don't", func(t *testing.T) {
+		h := authenticate(gluehttp.Authorize(slog.New(slog.DiscardHandler), pg, "write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+		rec := serveBearer(h, http.MethodGet, "/", "glue_pat_read", "")
+		is.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestAccessTokens(t *testing.T) {
+	t.Run("creates, lists, and revokes tokens", func(t *testing.T) {
+		h, store := newAccessTokensHandler(t, true)
+
+		rec := serveBearer(h, http.MethodPost, "/api/tokens", "", `{"name":"CLI","scopes":["read"]}`)
+		is.Equal(t, http.StatusCreated, rec.Code)
+		var created struct {
+			ID     string   `json:"id"`
+			Name   string   `json:"name"`
+			Scopes []string `json:"scopes"`
+			Token  string   `json:"token"`
+		}
+		is.NotError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		is.Equal(t, "at_CLI", created.ID)
+		is.Equal(t, "glue_pat_CLI", created.Token)
+		is.EqualSlice(t, []string{"read"}, created.Scopes)
+
+		rec = serveBearer(h, http.MethodGet, "/api/tokens", "", "")
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.True(t, strings.Contains(rec.Body.String(), `"id":"at_CLI"`))
+		is.True(t, !strings.Contains(rec.Body.String(), "glue_pat_"))
+
+		rec = serveBearer(h, http.MethodDelete, "/api/tokens/at_CLI", "", "")
+		is.Equal(t, http.StatusNoContent, rec.Code)
+		is.Equal(t, 0, len(store.tokens))
+
+		rec = serveBearer(h, http.MethodDelete, "/api/tokens/at_CLI", "", "")
+		is.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("forbids scopes the user doesn't have permissions for", func(t *testing.T) {
+		h, _ := newAccessTokensHandler(t, true)
+
+		rec := serveBearer(h, http.MethodPost, "/api/tokens", "", `{"name":"CLI","scopes":["admin"]}`)
+		is.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("responds with bad request for an expiry of more than ten years", func(t *testing.T) {
+		h, store := newAccessTokensHandler(t, true)
+
+		rec := serveBearer(h, http.MethodPost, "/api/tokens", "", `{"name":"CLI","scopes":["read"],"expiresInDays":3651}`)
+		is.Equal(t, http.StatusBadRequest, rec.Code)
+		is.Equal(t, 0, len(store.tokens))
+
+		rec = serveBearer(h, http.MethodPost, "/api/tokens", "", `{"name":"CLI","scopes":["read"],"expiresInDays":3650}`)
+		is.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("responds with 401 if not logged in", func(t *testing.T) {
+		h, _ := newAccessTokensHandler(t, false)
+
+		rec := serveBearer(h, http.MethodGet, "/api/tokens", "", "")
+		is.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("forbids managing tokens with an access token", func(t *testing.T) {
+		h, store := newAccessTokensHandler(t, false)
+		store.tokens["glue_pat_existing"] = model.AccessToken{ID: "at_existing", UserID: "u_123", Scopes: []model.Permission{"read"}}
+
+		rec := serveBearer(h, http.MethodPost, "/api/tokens", "glue_pat_existing", `{"name":"CLI","scopes":["write"]}`)
+		is.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func newAccessTokensHandler(t *testing.T, loggedIn bool) (http.Handler, *mockAccessTokenStore) {
+	t.Helper()
+
+	log := slog.New(slog.DiscardHandler)
+	store := &mockAccessTokenStore{tokens: map[string]model.AccessToken{}}
+	uac := &mockUserActiveChecker{active: true}
+
+	router := newAuthenticatedRouter(log, loggedIn, uac)
+	router.Use(gluehttp.AuthenticateBearer(log, store, uac))
+
+	gluehttp.AccessTokens(router, gluehttp.AccessTokensOptions{
+		Log:               log,
+		PermissionsGetter: &mockPermissionsGetter{permissions: []model.Permission{"read", "write"}},
+		Store:             store,
+	})
+
+	return router.Mux, store
+}
+
+func serveBearer(h http.Handler, method, target, token, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	h.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"encoding/json"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type NewAdminHandlerOptions struct {
+	// LogLevel can be changed at runtime through the admin handler. If nil, the log level is read-only and reported as unknown.
+	LogLevel *slog.LevelVar
+
+	// Routes to list in the route table. If nil, the route table is empty.
+	Routes chi.Routes
+}
+
+// NewAdminHandler for admin and debug endpoints, which must not be exposed publicly.
+// It serves:
+//   - GET /debug/pprof/ with the [pprof] profiles.
+//   - GET /debug/vars with [expvar] variables.
+//   - GET /build with build info as JSON, including the VCS revision.
+//   - GET /routes with the route table as JSON.
+//   - GET /log/level with the current log level, and PUT /log/level to change it with a level=debug|info|warn|error form value.
+//
+// It doesn't use sessions, CSP, or any of the other middleware on the public [Server] routes.
+func NewAdminHandler(opts NewAdminHandlerOptions) http.Handler {
+	mux := chi.NewRouter()
+
+	mux.HandleFunc("/debug/pprof/*", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.Get("/build", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, getBuildInfo())
+	})
+
+	mux.Get("/routes", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, getRouteTable(opts.Routes))
+	})
+
+	mux.Get("/log/level", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, logLevelResponse{Level: getLogLevel(opts.LogLevel)})
+	})
+
+	mux.Put("/log/level", func(w http.ResponseWriter, r *http.Request) {
+		if opts.LogLevel == nil {
+			writeJSON(w, http.StatusConflict, errorResponse{Error: "log level cannot be changed at runtime"})
+			return
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(r.FormValue("level"))); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		opts.LogLevel.Set(level)
+
+		writeJSON(w, http.StatusOK, logLevelResponse{Level: getLogLevel(opts.LogLevel)})
+	})
+
+	return mux
+}
+
+type buildInfo struct {
+	GoVersion string `json:"goVersion"`
+	Path      string `json:"path"`
+	Revision  string `json:"revision"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+}
+
+// getBuildInfo from [debug.ReadBuildInfo], with the revision "unknown" if not available.
+func getBuildInfo() buildInfo {
+	bi := buildInfo{Revision: "unknown"}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return bi
+	}
+
+	bi.GoVersion = info.GoVersion
+	bi.Path = info.Main.Path
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			bi.Revision = setting.Value
+		case "vcs.time":
+			bi.Time = setting.Value
+		case "vcs.modified":
+			bi.Modified = setting.Value == "true"
+		}
+	}
+
+	return bi
+}
+
+type route struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+// getRouteTable from the given routes, sorted by pattern and method.
+func getRouteTable(routes chi.Routes) []route {
+	table := []route{}
+	if routes == nil {
+		return table
+	}
+
+	_ = chi.Walk(routes, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		table = append(table, route{Method: method, Pattern: pattern})
+		return nil
+	})
+
+	slices.SortFunc(table, func(a, b route) int {
+		if c := strings.Compare(a.Pattern, b.Pattern); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	return table
+}
+
+type logLevelResponse struct {
+	Level string `json:"level"`
+}
+
+func getLogLevel(v *slog.LevelVar) string {
+	if v == nil {
+		return "unknown"
+	}
+	return strings.ToLower(v.Level().String())
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,86 @@
+package http_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+)
+
+func TestNewAdminHandler(t *testing.T) {
+	t.Run("serves pprof and expvar", func(t *testing.T) {
+		h := gluehttp.NewAdminHandler(gluehttp.NewAdminHandlerOptions{})
+
+		for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/vars"} {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			is.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("serves build info", func(t *testing.T) {
+		h := gluehttp.NewAdminHandler(gluehttp.NewAdminHandlerOptions{})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/build", nil))
+		is.Equal(t, http.StatusOK, rec.Code)
+
+		var info struct {
+			Revision string
+		}
+		err := json.Unmarshal(rec.Body.Bytes(), &info)
+		is.NotError(t, err)
+		is.True(t, info.Revision != "")
+	})
+
+	t.Run("serves the route table", func(t *testing.T) {
+		mux := chi.NewRouter()
+		mux.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+		mux.Post("/signup", func(w http.ResponseWriter, r *http.Request) {})
+
+		h := gluehttp.NewAdminHandler(gluehttp.NewAdminHandlerOptions{Routes: mux})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/routes", nil))
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, `[{"method":"GET","pattern":"/"},{"method":"POST","pattern":"/signup"}]`+"\n", rec.Body.String())
+	})
+
+	t.Run("gets and sets the log level", func(t *testing.T) {
+		var level slog.LevelVar
+		h := gluehttp.NewAdminHandler(gluehttp.NewAdminHandlerOptions{LogLevel: &level})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, `{"level":"info"}`+"\n", rec.Body.String())
+
+		rec = serveForm(h, http.MethodPut, "/log/level", url.Values{"level": {"debug"}})
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, `{"level":"debug"}`+"\n", rec.Body.String())
+		is.Equal(t, slog.LevelDebug, level.Level())
+	})
+
+	t.Run("rejects invalid log levels", func(t *testing.T) {
+		var level slog.LevelVar
+		h := gluehttp.NewAdminHandler(gluehttp.NewAdminHandlerOptions{LogLevel: &level})
+
+		rec := serveForm(h, http.MethodPut, "/log/level", url.Values{"level": {"verbose"}})
+		is.Equal(t, http.StatusBadRequest, rec.Code)
+		is.Equal(t, slog.LevelInfo, level.Level())
+	})
+
+	t.Run("cannot set the log level without a level var", func(t *testing.T) {
+		h := gluehttp.NewAdminHandler(gluehttp.NewAdminHandlerOptions{})
+
+		rec := serveForm(h, http.MethodPut, "/log/level", url.Values{"level": {"debug"}})
+		is.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"slices"
+	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -19,6 +20,7 @@ import (
 
 const contextUserIDKey = ContextKey("userID")
 const contextPermissionsKey = ContextKey("permissions")
+const contextAccessTokenKey = ContextKey("accessToken")
 
 const SessionUserIDKey = "userID"
 
@@ -98,12 +100,118 @@ func Authenticate(log *slog.Logger, sgd sessionGetterDestroyer, uac userActiveCh
 			}
 
 			// Store the user directly in the request context instead of having to use the session manager
-			ctx = context.WithValue(ctx, contextUserIDKey, &userID)
+			ctx = withUserID(ctx, &userID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+type accessTokenAuthenticator interface {
+	// AuthenticateAccessToken returns [model.ErrorTokenNotFound] or [model.ErrorTokenExpired] for tokens that can't be used.
+	AuthenticateAccessToken(ctx context.Context, token string) (model.AccessToken, error)
+}
+
+// AuthenticateBearer is [Middleware] to authenticate users with personal access tokens in the Authorization: Bearer header,
+// for API clients that don't have a session. If there is no Authorization header, the middleware just calls the next handler.
+// After authentication, the user ID is stored in the request context like with [Authenticate], and the access token can be
+// retrieved using [GetAccessTokenFromContext]. [Authorize] and [SavePermissionsInContext] limit permissions to the token scopes.
+// Invalid, expired, or revoked tokens, and tokens for inactive users, get a 401 problem response.
+func AuthenticateBearer(log *slog.Logger, ata accessTokenAuthenticator, uac userActiveChecker) Middleware {
+	tracer := otel.Tracer("maragu.dev/glue/http")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, span := tracer.Start(r.Context(), "http.AuthenticateBearer")
+			defer span.End()
+			r = r.WithContext(ctx)
+
+			scheme, token, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				writeBearerProblem(w, "authorization header must be a bearer token")
+				return
+			}
+
+			at, err := ata.AuthenticateAccessToken(ctx, token)
+			if err != nil {
+				if errors.Is(err, model.ErrorTokenNotFound) || errors.Is(err, model.ErrorTokenExpired) {
+					writeBearerProblem(w, err.Error())
+					return
+				}
+
+				log.InfoContext(ctx, "Error authenticating access token", "error", err)
+				writeProblem(w, http.StatusInternalServerError, "")
+				return
+			}
+
+			active, err := uac.IsUserActive(ctx, at.UserID)
+			if err != nil && !errors.Is(err, model.ErrorUserNotFound) {
+				log.InfoContext(ctx, "Error getting user after access token authentication", "error", err, "userID", at.UserID)
+				writeProblem(w, http.StatusInternalServerError, "")
+				return
+			}
+			if !active {
+				writeBearerProblem(w, model.ErrorUserInactive.Error())
+				return
+			}
+
+			// Add user and token IDs to the root span
+			if rootSpan := GetRootSpanFromContext(ctx); rootSpan != nil && rootSpan.IsRecording() {
+				rootSpan.SetAttributes(semconv.EnduserPseudoID(string(at.UserID)), attribute.String("enduser.token_id", string(at.ID)))
+			}
+
+			ctx = withUserID(ctx, &at.UserID)
+			ctx = context.WithValue(ctx, contextAccessTokenKey, &at)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// writeBearerProblem responds with 401 Unauthorized and an invalid_token challenge, see RFC 6750.
+func writeBearerProblem(w http.ResponseWriter, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	writeProblem(w, http.StatusUnauthorized, detail)
+}
+
+// GetAccessTokenFromContext, which is nil if the user is not authenticated with an access token.
+func GetAccessTokenFromContext(ctx context.Context) *model.AccessToken {
+	at := ctx.Value(contextAccessTokenKey)
+	if at == nil {
+		return nil
+	}
+	return at.(*model.AccessToken)
+}
+
+// restrictToScopes of the access token in the context, if any.
+func restrictToScopes(ctx context.Context, permissions []model.Permission) []model.Permission {
+	at := GetAccessTokenFromContext(ctx)
+	if at == nil {
+		return permissions
+	}
+
+	var restricted []model.Permission
+	for _, p := range permissions {
+		if slices.Contains(at.Scopes, p) {
+			restricted = append(restricted, p)
+		}
+	}
+	return restricted
+}
+
+// withUserID returns a context with the user ID, which may be nil to remove it.
+// The user ID is also recorded for [AccessLog], which runs before authentication and can't see the new context.
+func withUserID(ctx context.Context, id *model.UserID) context.Context {
+	if e, ok := ctx.Value(contextAccessLogEntryKey).(*accessLogEntry); ok {
+		e.userID = id
+	}
+	return context.WithValue(ctx, contextUserIDKey, id)
+}
+
 // GetUserIDFromContext, which may be nil if the user is not authenticated.
 func GetUserIDFromContext(ctx context.Context) *model.UserID {
 	id := ctx.Value(contextUserIDKey)
@@ -114,7 +222,52 @@ func GetUserIDFromContext(ctx context.Context) *model.UserID {
 	return id.(*model.UserID)
 }
 
+// Authorize is [Middleware] that requires a logged-in user with all the required permissions.
+// It's a shorthand for [AuthorizeWithOptions] with default options.
 func Authorize(log *slog.Logger, pg permissionsGetter, requiredPermissions ...model.Permission) Middleware {
+	return AuthorizeWithOptions(AuthorizeOptions{
+		Log:               log,
+		Permissions:       requiredPermissions,
+		PermissionsGetter: pg,
+	})
+}
+
+type AuthorizeOptions struct {
+	// AnyPermission allows users with any one of the [AuthorizeOptions.Permissions], instead of requiring all of them.
+	AnyPermission bool
+
+	// JSON makes all responses to unauthenticated or unauthorized requests JSON [ProblemResponse] 401 and 403 responses,
+	// for example for API routes. Requests authenticated with an access token, or accepting JSON, always get JSON responses.
+	JSON bool
+
+	Log *slog.Logger
+
+	// LoginURL to redirect anonymous users to, with a redirect query parameter to get back afterwards. Defaults to /login.
+	LoginURL string
+
+	// Page to render a forbidden page in for users without the required permissions.
+	// If nil, the response is a plain text 403 Forbidden.
+	Page html.PageFunc
+
+	// Permissions required.
+	Permissions []model.Permission
+
+	PermissionsGetter permissionsGetter
+}
+
+// AuthorizeWithOptions is [Middleware] that requires a logged-in user with the required permissions.
+// Anonymous users are redirected to the login page, and users without the permissions get 403 Forbidden.
+// For JSON requests, see [AuthorizeOptions.JSON], anonymous users get 401 Unauthorized instead of a redirect.
+func AuthorizeWithOptions(opts AuthorizeOptions) Middleware {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	if opts.LoginURL == "" {
+		opts.LoginURL = "/login"
+	}
+
+	log := opts.Log
 	tracer := otel.Tracer("maragu.dev/glue/http")
 
 	return func(next http.Handler) http.Handler {
@@ -123,16 +276,26 @@ func Authorize(log *slog.Logger, pg permissionsGetter, requiredPermissions ...mo
 			defer span.End()
 			r = r.WithContext(ctx)
 
+			isJSON := opts.JSON || isJSONRequest(r)
+
 			userID := GetUserIDFromContext(ctx)
 
 			if userID == nil {
-				http.Redirect(w, r, "/login?redirect="+url.QueryEscape(r.URL.Path), http.StatusTemporaryRedirect)
+				if isJSON {
+					writeProblem(w, http.StatusUnauthorized, "")
+					return
+				}
+				http.Redirect(w, r, getLoginURL(opts.LoginURL, r.URL.Path), http.StatusTemporaryRedirect)
 				return
 			}
 
-			permissions, err := pg.GetPermissions(ctx, *userID)
+			permissions, err := getPermissions(ctx, opts.PermissionsGetter, *userID)
 			if err != nil {
 				log.InfoContext(ctx, "Error getting permissions", "error", err, "userID", userID)
+				if isJSON {
+					writeProblem(w, http.StatusInternalServerError, "")
+					return
+				}
 				http.Error(w, "error getting permissions", http.StatusInternalServerError)
 				return
 			}
@@ -146,28 +309,82 @@ func Authorize(log *slog.Logger, pg permissionsGetter, requiredPermissions ...mo
 				rootSpan.SetAttributes(attribute.StringSlice("enduser.permissions", permissionStrings))
 			}
 
-			hasRequiredPermissions := true
-			for _, requiredPermission := range requiredPermissions {
-				if !slices.Contains(permissions, requiredPermission) {
-					hasRequiredPermissions = false
-					break
+			if !hasPermissions(permissions, opts.Permissions, opts.AnyPermission) {
+				switch {
+				case isJSON:
+					writeProblem(w, http.StatusForbidden, "missing permissions")
+				case opts.Page != nil:
+					w.Header().Set("Content-Type", "text/html; charset=utf-8")
+					w.WriteHeader(http.StatusForbidden)
+					_ = html.ForbiddenPage(opts.Page).Render(w)
+				default:
+					http.Error(w, "unauthorized", http.StatusForbidden)
 				}
-			}
-
-			if !hasRequiredPermissions {
-				http.Error(w, "unauthorized", http.StatusForbidden)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			ctx = context.WithValue(ctx, contextPermissionsKey, permissions)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// hasPermissions returns whether the user permissions include all of the required permissions, or any of them if anyOf is true.
+// No required permissions are always satisfied.
+func hasPermissions(permissions, required []model.Permission, anyOf bool) bool {
+	if len(required) == 0 {
+		return true
+	}
+
+	for _, p := range required {
+		has := slices.Contains(permissions, p)
+		if anyOf && has {
+			return true
+		}
+		if !anyOf && !has {
+			return false
+		}
+	}
+	return !anyOf
+}
+
+// getLoginURL with a redirect query parameter back to the given path.
+func getLoginURL(loginURL, path string) string {
+	separator := "?"
+	if strings.Contains(loginURL, "?") {
+		separator = "&"
+	}
+	return loginURL + separator + "redirect=" + url.QueryEscape(path)
+}
+
+// isJSONRequest returns whether the request is authenticated with an access token, or accepts JSON but not HTML.
+func isJSONRequest(r *http.Request) bool {
+	if GetAccessTokenFromContext(r.Context()) != nil {
+		return true
+	}
+
+	accept := r.Header.Get("Accept")
+	return (strings.Contains(accept, "application/json") || strings.Contains(accept, "+json")) && !strings.Contains(accept, "text/html")
+}
+
 type permissionsGetter interface {
 	GetPermissions(ctx context.Context, id model.UserID) ([]model.Permission, error)
 }
 
+// getPermissions for the user from the context if they've already been saved there during this request,
+// so permissions are only looked up once per request. Otherwise, get them with pg and limit them to the access token scopes.
+func getPermissions(ctx context.Context, pg permissionsGetter, userID model.UserID) ([]model.Permission, error) {
+	if permissions, ok := ctx.Value(contextPermissionsKey).([]model.Permission); ok {
+		return permissions, nil
+	}
+
+	permissions, err := pg.GetPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return restrictToScopes(ctx, permissions), nil
+}
+
 func SavePermissionsInContext(log *slog.Logger, pg permissionsGetter) Middleware {
 	tracer := otel.Tracer("maragu.dev/glue/http")
 
@@ -184,7 +401,7 @@ func SavePermissionsInContext(log *slog.Logger, pg permissionsGetter) Middleware
 				return
 			}
 
-			permissions, err := pg.GetPermissions(ctx, *userID)
+			permissions, err := getPermissions(ctx, pg, *userID)
 			if err != nil {
 				log.ErrorContext(ctx, "Error getting permissions", "error", err, "userID", userID)
 				http.Error(w, "error getting permissions", http.StatusInternalServerError)
@@ -206,7 +423,7 @@ func GetPermissionsFromContext(ctx context.Context) []model.Permission {
 }
 
 // Logout creates an http.Handler for logging out.
-// It just destroys the current user session.
+// It just destroys the current user session, and redirects with [Redirect], so it also works for htmx requests.
 func Logout(r *Router, log *slog.Logger, sd sessionDestroyer, page html.PageFunc) {
 	r.Post("/logout", func(props html.PageProps) (g.Node, error) {
 		redirect := props.R.URL.Query().Get("redirect")
@@ -216,7 +433,7 @@ func Logout(r *Router, log *slog.Logger, sd sessionDestroyer, page html.PageFunc
 
 		userID := GetUserIDFromContext(props.Ctx)
 		if userID == nil {
-			http.Redirect(props.W, props.R, redirect, http.StatusFound)
+			Redirect(props.W, props.R, redirect, http.StatusFound)
 			return nil, nil
 		}
 
@@ -225,7 +442,7 @@ func Logout(r *Router, log *slog.Logger, sd sessionDestroyer, page html.PageFunc
 			return html.ErrorPage(page), err
 		}
 
-		http.Redirect(props.W, props.R, redirect, http.StatusFound)
+		Redirect(props.W, props.R, redirect, http.StatusFound)
 
 		return nil, nil
 	})
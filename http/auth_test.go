@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -45,6 +47,29 @@ func (m *mockUserActiveChecker) IsUserActive(ctx context.Context, id model.UserI
 	return m.active, m.err
 }
 
+// serveForm to the handler with the values url-encoded in the request body, and return the recorded response.
+func serveForm(h http.Handler, method, target string, values url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// testPage renders the page children without a layout.
+func testPage(props html.PageProps, children ...g.Node) g.Node {
+	return g.Group(children)
+}
+
+// newAuthenticatedRouter with [gluehttp.Authenticate] using a [mockSessionManager], so requests are from user u_123 if loggedIn.
+func newAuthenticatedRouter(log *slog.Logger, loggedIn bool, uac interface {
+	IsUserActive(ctx context.Context, id model.UserID) (bool, error)
+}) *gluehttp.Router {
+	router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+	router.Use(gluehttp.Authenticate(log, &mockSessionManager{exists: loggedIn}, uac))
+	return router
+}
+
 func TestAuthenticate(t *testing.T) {
 	tests := []struct {
 		name                    string
@@ -229,6 +254,71 @@ func TestAuthorize(t *testing.T) {
 	}
 }
 
+func TestAuthorizeWithOptions(t *testing.T) {
+	serve := func(opts gluehttp.AuthorizeOptions, loggedIn bool, accept string) *httptest.ResponseRecorder {
+		h := gluehttp.AuthorizeWithOptions(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if loggedIn {
+			userID := model.UserID("u_123")
+			req = req.WithContext(context.WithValue(req.Context(), gluehttp.ContextKey("userID"), &userID))
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	pg := &mockPermissionsGetter{permissions: []model.Permission{"read"}}
+
+	t.Run("redirects to the configured login URL", func(t *testing.T) {
+		rec := serve(gluehttp.AuthorizeOptions{LoginURL: "/auth?from=app", PermissionsGetter: pg}, false, "")
+		is.Equal(t, http.StatusTemporaryRedirect, rec.Code)
+		is.Equal(t, "/auth?from=app&redirect=%2Fprotected", rec.Header().Get("Location"))
+	})
+
+	t.Run("renders a forbidden page", func(t *testing.T) {
+		rec := serve(gluehttp.AuthorizeOptions{Page: testPage, Permissions: []model.Permission{"write"}, PermissionsGetter: pg}, true, "")
+		is.Equal(t, http.StatusForbidden, rec.Code)
+		is.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		is.True(t, strings.Contains(rec.Body.String(), "Forbidden"))
+	})
+
+	t.Run("responds with JSON 401 and 403", func(t *testing.T) {
+		opts := gluehttp.AuthorizeOptions{JSON: true, Permissions: []model.Permission{"write"}, PermissionsGetter: pg}
+
+		rec := serve(opts, false, "")
+		is.Equal(t, http.StatusUnauthorized, rec.Code)
+		is.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+		rec = serve(opts, true, "")
+		is.Equal(t, http.StatusForbidden, rec.Code)
+		is.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("responds with JSON if the request accepts JSON", func(t *testing.T) {
+		rec := serve(gluehttp.AuthorizeOptions{PermissionsGetter: pg}, false, "application/json")
+		is.Equal(t, http.StatusUnauthorized, rec.Code)
+		is.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("allows any of the permissions", func(t *testing.T) {
+		rec := serve(gluehttp.AuthorizeOptions{AnyPermission: true, Permissions: []model.Permission{"read", "write"}, PermissionsGetter: pg}, true, "")
+		is.Equal(t, http.StatusOK, rec.Code)
+
+		rec = serve(gluehttp.AuthorizeOptions{AnyPermission: true, Permissions: []model.Permission{"write", "admin"}, PermissionsGetter: pg}, true, "")
+		is.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("requires all of the permissions by default", func(t *testing.T) {
+		rec := serve(gluehttp.AuthorizeOptions{Permissions: []model.Permission{"read", "write"}, PermissionsGetter: pg}, true, "")
+		is.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
 func TestSavePermissionsInContext(t *testing.T) {
 	tests := []struct {
 		name                    string
@@ -307,8 +397,10 @@ func TestLogout(t *testing.T) {
 		userIDInContext      bool
 		destroyError         error
 		queryRedirect        string
+		htmx                 bool
 		expectStatus         int
 		expectRedirect       string
+		expectHXRedirect     string
 		expectDestroySession bool
 	}{
 		{
@@ -341,6 +433,15 @@ func TestLogout(t *testing.T) {
 			expectRedirect:       "/dashboard",
 			expectDestroySession: false,
 		},
+		{
+			name:                 "htmx logout redirects with a header",
+			userIDInContext:      true,
+			queryRedirect:        "/dashboard",
+			htmx:                 true,
+			expectStatus:         http.StatusOK,
+			expectHXRedirect:     "/dashboard",
+			expectDestroySession: true,
+		},
 		{
 			name:                 "destroy session error",
 			userIDInContext:      true,
@@ -362,6 +463,9 @@ func TestLogout(t *testing.T) {
 			gluehttp.Logout(router, slog.New(slog.DiscardHandler), sm, mockPage)
 
 			req := httptest.NewRequest(http.MethodPost, "/logout?redirect="+test.queryRedirect, nil)
+			if test.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
 
 			if test.userIDInContext {
 				userID := model.UserID("u_123")
@@ -375,6 +479,7 @@ func TestLogout(t *testing.T) {
 			is.Equal(t, test.expectStatus, rec.Code)
 			is.Equal(t, test.expectDestroySession, sm.destroyed)
 			is.Equal(t, test.expectRedirect, rec.Header().Get("Location"))
+			is.Equal(t, test.expectHXRedirect, rec.Header().Get("HX-Redirect"))
 		})
 	}
 }
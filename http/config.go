@@ -0,0 +1,29 @@
+package http
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Config for [NewServer], to load with [maragu.dev/glue/config.Load].
+type Config struct {
+	Address       string        `env:"SERVER_ADDRESS" default:":8080"`
+	AdminAddress  string        `env:"SERVER_ADMIN_ADDRESS"`
+	BaseURL       string        `env:"BASE_URL" default:"http://localhost:8080"`
+	SecureCookie  bool          `env:"SECURE_COOKIE" default:"true"`
+	ShutdownDelay time.Duration `env:"SERVER_SHUTDOWN_DELAY" default:"0s"`
+	WriteTimeout  time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"10s"`
+}
+
+// ServerOptions from the config. Remaining options must be set by the caller.
+func (c Config) ServerOptions(log *slog.Logger) NewServerOptions {
+	return NewServerOptions{
+		Address:       c.Address,
+		AdminAddress:  c.AdminAddress,
+		BaseURL:       c.BaseURL,
+		Log:           log,
+		SecureCookie:  c.SecureCookie,
+		ShutdownDelay: c.ShutdownDelay,
+		WriteTimeout:  c.WriteTimeout,
+	}
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	g "maragu.dev/gomponents"
+
+	"maragu.dev/glue/email"
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+type emailChangeStore[U any] interface {
+	CreateEmailChangeToken(ctx context.Context, userID model.UserID, email model.EmailAddress, lifetime time.Duration) (string, error)
+	// ConfirmEmailChange updates the user's email address with u, and returns [model.ErrorTokenNotFound],
+	// [model.ErrorTokenExpired], or [model.ErrorEmailConflict].
+	ConfirmEmailChange(ctx context.Context, token string, u U) (model.UserID, model.EmailAddress, error)
+}
+
+type userGetter interface {
+	// GetUser returns [model.ErrorUserNotFound] if there's no user with the ID.
+	GetUser(ctx context.Context, id model.UserID) (model.User, error)
+}
+
+// EmailChangeOptions for [EmailChange], where U is the type of the user email updater that the [EmailChangeOptions.Store]
+// takes on confirmation, such as [maragu.dev/glue/sql.UserEmailUpdater].
+type EmailChangeOptions[U any] struct {
+	Log *slog.Logger
+
+	// LoginURL to redirect anonymous users to, with a redirect query parameter to get back afterwards. Defaults to /login.
+	LoginURL string
+
+	// Page to render the email change pages in.
+	Page html.PageFunc
+
+	// Sender for the confirmation and notification emails, using the new-email-confirmation and
+	// new-email-notification templates from [email.GetTemplates].
+	Sender email.Sender
+
+	// Store for email change tokens, such as [maragu.dev/glue/sql.Helper].
+	Store emailChangeStore[U]
+
+	// TokenLifetime is how long confirmation links are valid for. Defaults to 24 hours.
+	TokenLifetime time.Duration
+
+	// UserEmailUpdater is passed to [EmailChangeOptions.Store] on confirmation, to update the user's email address
+	// in the app's user store.
+	UserEmailUpdater U
+
+	// Users are looked up in the app's user store, to get their current name and email address.
+	Users userGetter
+}
+
+// EmailChange registers routes for a logged-in user to change their email address:
+//   - GET /profile/email shows a form for the new email address.
+//   - POST /profile/email sends a confirmation link to the new address, and a notification to the old one.
+//   - GET /profile/email?token= confirms the change, and only then changes the address.
+//
+// Users who aren't logged in are redirected to [EmailChangeOptions.LoginURL] for the form, but the confirmation link works without being logged in,
+// since it proves access to the new address.
+func EmailChange[U any](r *Router, opts EmailChangeOptions[U]) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	if opts.LoginURL == "" {
+		opts.LoginURL = "/login"
+	}
+
+	if opts.TokenLifetime == 0 {
+		opts.TokenLifetime = 24 * time.Hour
+	}
+
+	log := opts.Log
+
+	r.Get("/profile/email", func(props html.PageProps) (g.Node, error) {
+		if token := props.R.URL.Query().Get("token"); token != "" {
+			return confirmEmailChange(props, opts, token)
+		}
+
+		user, ok, err := getLoggedInUser(props, opts.LoginURL, opts.Users)
+		if err != nil {
+			return getLoggedInUserErrorPage(props, opts, err), err
+		}
+		if !ok {
+			return nil, nil
+		}
+
+		return html.ChangeEmailPage(opts.Page, user.Email), nil
+	})
+
+	r.Post("/profile/email", func(props html.PageProps) (g.Node, error) {
+		user, ok, err := getLoggedInUser(props, opts.LoginURL, opts.Users)
+		if err != nil {
+			return getLoggedInUserErrorPage(props, opts, err), err
+		}
+		if !ok {
+			return nil, nil
+		}
+
+		newEmail := model.EmailAddress(props.R.FormValue("email")).ToLower()
+		if !newEmail.IsValid() || newEmail == user.Email {
+			props.W.WriteHeader(http.StatusBadRequest)
+			return html.ChangeEmailPage(opts.Page, user.Email), nil
+		}
+
+		// Conflicts are only checked on confirmation, so this doesn't reveal which addresses have accounts
+		token, err := opts.Store.CreateEmailChangeToken(props.Ctx, user.ID, newEmail, opts.TokenLifetime)
+		if err != nil {
+			log.ErrorContext(props.Ctx, "Error creating email change token", "error", err, "userID", user.ID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		if err := opts.Sender.SendTransactional(props.Ctx, user.Name, newEmail, "Confirm your new email address",
+			"Click the link to confirm your new email address.", "new-email-confirmation", model.Keywords{
+				"name":     user.Name,
+				"newEmail": newEmail.String(),
+				"oldEmail": user.Email.String(),
+				"token":    token,
+			}); err != nil {
+			log.ErrorContext(props.Ctx, "Error sending email change confirmation", "error", err, "userID", user.ID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		// The notification must not contain the token, or anyone with access to the old address could confirm the change
+		if err := opts.Sender.SendTransactional(props.Ctx, user.Name, user.Email, "Your email address is being changed",
+			"Your email address for log in is being changed.", "new-email-notification", model.Keywords{
+				"name":     user.Name,
+				"newEmail": newEmail.String(),
+				"oldEmail": user.Email.String(),
+			}); err != nil {
+			log.ErrorContext(props.Ctx, "Error sending email change notification", "error", err, "userID", user.ID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		return html.ConfirmEmailChangePage(opts.Page, newEmail), nil
+	})
+}
+
+func confirmEmailChange[U any](props html.PageProps, opts EmailChangeOptions[U], token string) (g.Node, error) {
+	userID, newEmail, err := opts.Store.ConfirmEmailChange(props.Ctx, token, opts.UserEmailUpdater)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrorTokenNotFound), errors.Is(err, model.ErrorTokenExpired):
+			props.W.WriteHeader(http.StatusBadRequest)
+			return html.InvalidLinkPage(opts.Page), nil
+		case errors.Is(err, model.ErrorEmailConflict):
+			props.W.WriteHeader(http.StatusConflict)
+			return html.EmailConflictPage(opts.Page), nil
+		default:
+			opts.Log.ErrorContext(props.Ctx, "Error confirming email change", "error", err)
+			return html.ErrorPage(opts.Page), err
+		}
+	}
+
+	opts.Log.InfoContext(props.Ctx, "Changed email", "userID", userID)
+	return html.EmailChangedPage(opts.Page, newEmail), nil
+}
+
+// getLoggedInUserErrorPage for an error from [getLoggedInUser], which is only logged if it's not an expected client [Error],
+// like while impersonating.
+func getLoggedInUserErrorPage[U any](props html.PageProps, opts EmailChangeOptions[U], err error) g.Node {
+	var httpErr Error
+	if errors.As(err, &httpErr) && httpErr.StatusCode() < http.StatusInternalServerError {
+		return html.ForbiddenPage(opts.Page)
+	}
+
+	opts.Log.ErrorContext(props.Ctx, "Error getting user", "error", err)
+	return html.ErrorPage(opts.Page)
+}
+
+// getLoggedInUser from the request context, redirecting to the login URL and returning false if there's none.
+// Admins impersonating the user get an [Error].
+func getLoggedInUser(props html.PageProps, loginURL string, ug userGetter) (model.User, bool, error) {
+	if props.UserID == nil {
+		http.Redirect(props.W, props.R, getLoginURL(loginURL, props.R.URL.Path), http.StatusFound)
+		return model.User{}, false, nil
+	}
+
+	if props.Impersonating {
+		return model.User{}, false, Error{Code: http.StatusForbidden, Err: errors.New("can't change email while impersonating")}
+	}
+
+	user, err := ug.GetUser(props.Ctx, *props.UserID)
+	if err != nil {
+		return model.User{}, false, err
+	}
+	return user, true, nil
+}
@@ -0,0 +1,160 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type mockEmailChangeStore struct {
+	tokens map[string]model.EmailAddress
+}
+
+func (m *mockEmailChangeStore) CreateEmailChangeToken(ctx context.Context, userID model.UserID, email model.EmailAddress, lifetime time.Duration) (string, error) {
+	token := "t_" + email.String()
+	m.tokens[token] = email
+	return token, nil
+}
+
+func (m *mockEmailChangeStore) ConfirmEmailChange(ctx context.Context, token string, u *mockUserStore) (model.UserID, model.EmailAddress, error) {
+	email, ok := m.tokens[token]
+	if !ok {
+		return "", "", model.ErrorTokenNotFound
+	}
+	if _, err := u.GetUserByEmail(ctx, email); err == nil {
+		return "", "", model.ErrorEmailConflict
+	}
+	delete(m.tokens, token)
+	u.users[0].Email = email
+	return u.users[0].ID, email, nil
+}
+
+func TestEmailChange(t *testing.T) {
+	t.Run("sends a confirmation and a notification, and changes the email on confirmation", func(t *testing.T) {
+		h, sender, users := newEmailChangeHandler(t, true, gluehttp.EmailChangeOptions[*mockUserStore]{})
+
+		rec := serveForm(h, http.MethodPost, "/profile/email", url.Values{"email": {"New@example.com"}})
+		is.Equal(t, http.StatusOK, rec.Code)
+
+		is.Equal(t, 2, len(sender.sent))
+		is.Equal(t, model.EmailAddress("new@example.com"), sender.sent[0].email)
+		is.Equal(t, "new-email-confirmation", sender.sent[0].template)
+		is.Equal(t, "old@example.com", sender.sent[0].kw["oldEmail"])
+		is.Equal(t, "new@example.com", sender.sent[0].kw["newEmail"])
+		is.Equal(t, model.EmailAddress("old@example.com"), sender.sent[1].email)
+		is.Equal(t, "new-email-notification", sender.sent[1].template)
+		_, hasToken := sender.sent[1].kw["token"]
+		is.True(t, !hasToken)
+
+		// Not changed before confirmation
+		is.Equal(t, model.EmailAddress("old@example.com"), users.users[0].Email)
+
+		rec = serveForm(h, http.MethodGet, "/profile/email?token="+sender.sent[0].kw["token"], nil)
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.True(t, strings.Contains(rec.Body.String(), "Email changed"))
+		is.Equal(t, model.EmailAddress("new@example.com"), users.users[0].Email)
+	})
+
+	t.Run("shows a conflict page if another user has the email on confirmation", func(t *testing.T) {
+		h, sender, users := newEmailChangeHandler(t, true, gluehttp.EmailChangeOptions[*mockUserStore]{})
+
+		rec := serveForm(h, http.MethodPost, "/profile/email", url.Values{"email": {"taken@example.com"}})
+		is.Equal(t, http.StatusOK, rec.Code)
+
+		users.users = append(users.users, model.User{ID: "u_456", Email: "taken@example.com", Active: true})
+
+		rec = serveForm(h, http.MethodGet, "/profile/email?token="+sender.sent[0].kw["token"], nil)
+		is.Equal(t, http.StatusConflict, rec.Code)
+		is.Equal(t, model.EmailAddress("old@example.com"), users.users[0].Email)
+	})
+
+	t.Run("shows an invalid link page for an unknown token", func(t *testing.T) {
+		h, _, _ := newEmailChangeHandler(t, false, gluehttp.EmailChangeOptions[*mockUserStore]{})
+
+		rec := serveForm(h, http.MethodGet, "/profile/email?token=doesnotexist", nil)
+		is.Equal(t, http.StatusBadRequest, rec.Code)
+		is.True(t, strings.Contains(rec.Body.String(), "Invalid link"))
+	})
+
+	t.Run("redirects to login if not logged in", func(t *testing.T) {
+		h, sender, _ := newEmailChangeHandler(t, false, gluehttp.EmailChangeOptions[*mockUserStore]{})
+
+		rec := serveForm(h, http.MethodPost, "/profile/email", url.Values{"email": {"new@example.com"}})
+		is.Equal(t, http.StatusFound, rec.Code)
+		is.Equal(t, "/login?redirect=%2Fprofile%2Femail", rec.Header().Get("Location"))
+		is.Equal(t, 0, len(sender.sent))
+	})
+
+	t.Run("redirects to the configured login URL if not logged in", func(t *testing.T) {
+		h, _, _ := newEmailChangeHandler(t, false, gluehttp.EmailChangeOptions[*mockUserStore]{LoginURL: "/signin"})
+
+		rec := serveForm(h, http.MethodGet, "/profile/email", nil)
+		is.Equal(t, http.StatusFound, rec.Code)
+		is.Equal(t, "/signin?redirect=%2Fprofile%2Femail", rec.Header().Get("Location"))
+	})
+
+	t.Run("responds with bad request for the same email address", func(t *testing.T) {
+		h, sender, _ := newEmailChangeHandler(t, true, gluehttp.EmailChangeOptions[*mockUserStore]{})
+
+		rec := serveForm(h, http.MethodPost, "/profile/email", url.Values{"email": {"old@example.com"}})
+		is.Equal(t, http.StatusBadRequest, rec.Code)
+		is.Equal(t, 0, len(sender.sent))
+	})
+
+	t.Run("responds with forbidden without logging an error while impersonating", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := slog.New(slog.NewTextHandler(&buf, nil))
+		users := &mockUserStore{users: []model.User{{ID: "u_123", Email: "old@example.com", Active: true}}}
+		sender := &mockSender{}
+
+		router := newAuthenticatedRouter(log, true, users)
+		router.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				adminUserID := model.UserID("u_admin")
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), gluehttp.ContextKey("realUserID"), &adminUserID)))
+			})
+		})
+		gluehttp.EmailChange(router, gluehttp.EmailChangeOptions[*mockUserStore]{
+			Log:              log,
+			Page:             testPage,
+			Sender:           sender,
+			Store:            &mockEmailChangeStore{tokens: map[string]model.EmailAddress{}},
+			UserEmailUpdater: users,
+			Users:            users,
+		})
+
+		rec := serveForm(router.Mux, http.MethodPost, "/profile/email", url.Values{"email": {"new@example.com"}})
+		is.Equal(t, http.StatusForbidden, rec.Code)
+		is.Equal(t, 0, len(sender.sent))
+		is.True(t, !strings.Contains(buf.String(), "level=ERROR"))
+	})
+}
+
+func newEmailChangeHandler(t *testing.T, loggedIn bool, opts gluehttp.EmailChangeOptions[*mockUserStore]) (http.Handler, *mockSender, *mockUserStore) {
+	t.Helper()
+
+	log := slog.New(slog.DiscardHandler)
+	sender := &mockSender{}
+	users := &mockUserStore{users: []model.User{{ID: "u_123", Name: "Me", Email: "old@example.com", Active: true}}}
+	router := newAuthenticatedRouter(log, loggedIn, users)
+
+	opts.Log = log
+	opts.Page = testPage
+	opts.Sender = sender
+	opts.Store = &mockEmailChangeStore{tokens: map[string]model.EmailAddress{}}
+	opts.UserEmailUpdater = users
+	opts.Users = users
+	gluehttp.EmailChange(router, opts)
+
+	return router.Mux, sender, users
+}
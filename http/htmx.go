@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// IsHTMXRequest returns whether the request was made by htmx, from the HX-Request header.
+func IsHTMXRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// Redirect like [http.Redirect], except for htmx requests, which get an HX-Redirect header and 200 OK instead.
+// htmx follows regular redirects in the background and swaps the result into the target element,
+// which isn't what you want when redirecting to a different page.
+func Redirect(w http.ResponseWriter, r *http.Request, url string, code int) {
+	if IsHTMXRequest(r) {
+		HXRedirect(w, url)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, url, code)
+}
+
+// HXRedirect sets the HX-Redirect response header, so htmx does a full page load of the URL.
+func HXRedirect(w http.ResponseWriter, url string) {
+	w.Header().Set("HX-Redirect", url)
+}
+
+// HXTrigger sets the HX-Trigger response header, so htmx triggers the given events on the target element.
+func HXTrigger(w http.ResponseWriter, events ...string) {
+	w.Header().Set("HX-Trigger", strings.Join(events, ", "))
+}
+
+// HXRetarget sets the HX-Retarget response header, so htmx swaps the response into the element matching the CSS selector.
+func HXRetarget(w http.ResponseWriter, selector string) {
+	w.Header().Set("HX-Retarget", selector)
+}
@@ -0,0 +1,89 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	g "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+	"maragu.dev/is"
+
+	"maragu.dev/glue/html"
+	gluehttp "maragu.dev/glue/http"
+)
+
+func TestRouter_htmx(t *testing.T) {
+	page := func(props html.PageProps, children ...g.Node) g.Node {
+		return Body(children...)
+	}
+
+	router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+	router.Get("/", func(props html.PageProps) (g.Node, error) {
+		return html.PageOrFragment(page, props, P(g.Text(props.HTMX.Target))), nil
+	})
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		expectBody string
+	}{
+		{name: "renders the full page for regular requests", expectBody: "<body><p></p></body>"},
+		{
+			name:       "renders just the fragment for htmx requests",
+			header:     http.Header{"Hx-Request": {"true"}, "Hx-Target": {"things"}},
+			expectBody: "<p>things</p>",
+		},
+		{
+			name:       "renders the full page for boosted htmx requests",
+			header:     http.Header{"Hx-Request": {"true"}, "Hx-Boosted": {"true"}},
+			expectBody: "<body><p></p></body>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header = test.header
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			router.Mux.ServeHTTP(rec, req)
+
+			is.Equal(t, http.StatusOK, rec.Code)
+			is.Equal(t, test.expectBody, rec.Body.String())
+			is.Equal(t, "HX-Request", rec.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	t.Run("redirects regular requests", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		gluehttp.Redirect(rec, httptest.NewRequest(http.MethodPost, "/", nil), "/things", http.StatusFound)
+
+		is.Equal(t, http.StatusFound, rec.Code)
+		is.Equal(t, "/things", rec.Header().Get("Location"))
+	})
+
+	t.Run("sets HX-Redirect for htmx requests", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("HX-Request", "true")
+		gluehttp.Redirect(rec, req, "/things", http.StatusFound)
+
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, "/things", rec.Header().Get("HX-Redirect"))
+		is.Equal(t, "", rec.Header().Get("Location"))
+	})
+}
+
+func TestHXResponseHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gluehttp.HXTrigger(rec, "thingCreated", "listChanged")
+	gluehttp.HXRetarget(rec, "#errors")
+
+	is.Equal(t, "thingCreated, listChanged", rec.Header().Get("HX-Trigger"))
+	is.Equal(t, "#errors", rec.Header().Get("HX-Retarget"))
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+const (
+	defaultIdempotencyTTL         = 24 * time.Hour
+	maxIdempotencyKeyLength       = 255
+	maxIdempotentRequestBodyBytes = 10 << 20
+)
+
+type idempotencyStore interface {
+	StartIdempotentRequest(ctx context.Context, key, requestHash string, ttl time.Duration) (*model.IdempotentResponse, error)
+	CompleteIdempotentRequest(ctx context.Context, key string, resp model.IdempotentResponse) error
+	CancelIdempotentRequest(ctx context.Context, key string) error
+}
+
+type IdempotencyOptions struct {
+	// FormField with the idempotency key, used for url-encoded forms without an Idempotency-Key header.
+	// Defaults to [html.IdempotencyKeyField], see [html.IdempotencyKeyInput].
+	FormField string
+
+	Log *slog.Logger
+
+	// Page to render error pages in. If nil, errors are plain text.
+	Page html.PageFunc
+
+	// Store for idempotency keys and responses, such as [maragu.dev/glue/sql.Helper].
+	Store idempotencyStore
+
+	// TTL of idempotency keys, after which a repeated request is handled again. Defaults to 24 hours.
+	TTL time.Duration
+}
+
+// Idempotency is [Middleware] that replays the first response to POST and PATCH requests with the same idempotency key,
+// from the Idempotency-Key header or the [IdempotencyOptions.FormField], so double form submissions and client retries
+// don't repeat the action. Replayed responses have an Idempotent-Replayed: true header.
+// Keys are scoped to the user ID from [GetUserIDFromContext], so it must come after [Authenticate].
+//
+// Reusing a key for a different request responds with 422 Unprocessable Entity,
+// and repeating a request while the first is still being handled responds with 409 Conflict.
+// Server errors and client disconnects aren't stored, so the request can be retried.
+func Idempotency(opts IdempotencyOptions) Middleware {
+	if opts.FormField == "" {
+		opts.FormField = html.IdempotencyKeyField
+	}
+
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	if opts.TTL == 0 {
+		opts.TTL = defaultIdempotencyTTL
+	}
+
+	log := opts.Log
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost && r.Method != http.MethodPatch {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+
+			key := r.Header.Get("Idempotency-Key")
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			isForm := mediaType == "application/x-www-form-urlencoded"
+			if key == "" && !isForm {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBodyBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					writeIdempotencyError(w, r, opts.Page, http.StatusRequestEntityTooLarge, "")
+					return
+				}
+				writeIdempotencyError(w, r, opts.Page, http.StatusBadRequest, "error reading request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if key == "" {
+				values, err := url.ParseQuery(string(body))
+				if err != nil {
+					writeIdempotencyError(w, r, opts.Page, http.StatusBadRequest, "error parsing form")
+					return
+				}
+				key = values.Get(opts.FormField)
+			}
+
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				writeIdempotencyError(w, r, opts.Page, http.StatusBadRequest, "idempotency key too long")
+				return
+			}
+
+			scope := "anonymous"
+			if userID := GetUserIDFromContext(ctx); userID != nil {
+				scope = userID.String()
+			}
+			key = scope + ":" + key
+
+			h := sha256.New()
+			_, _ = io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+			_, _ = h.Write(body)
+			requestHash := hex.EncodeToString(h.Sum(nil))
+
+			resp, err := opts.Store.StartIdempotentRequest(ctx, key, requestHash, opts.TTL)
+			if err != nil {
+				code, detail := getProblem(err)
+				if code >= http.StatusInternalServerError {
+					log.ErrorContext(ctx, "Error starting idempotent request", "error", err)
+				}
+				writeIdempotencyError(w, r, opts.Page, code, detail)
+				return
+			}
+
+			if resp != nil {
+				for k, v := range resp.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(resp.Status)
+				_, _ = w.Write(resp.Body)
+				return
+			}
+
+			var b bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&b)
+
+			// Store the response even if the client has disconnected, so a retry gets it
+			storeCtx := context.WithoutCancel(ctx)
+
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				// The handler panicked or failed, or storing the response failed, so release the key for retries
+				if err := opts.Store.CancelIdempotentRequest(storeCtx, key); err != nil {
+					log.ErrorContext(ctx, "Error cancelling idempotent request", "error", err)
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError || status == statusClientClosedRequest {
+				return
+			}
+
+			header := getIdempotentResponseHeader(w.Header())
+
+			if err := opts.Store.CompleteIdempotentRequest(storeCtx, key, model.IdempotentResponse{
+				Status: status,
+				Header: header,
+				Body:   b.Bytes(),
+			}); err != nil {
+				log.ErrorContext(ctx, "Error completing idempotent request", "error", err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// getIdempotentResponseHeader to store for replaying, without cookies, and without the encoding headers set by
+// [middleware.Compress], because the stored body is uncompressed. Compression is applied again on replay.
+func getIdempotentResponseHeader(h http.Header) http.Header {
+	header := h.Clone()
+	header.Del("Set-Cookie")
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+
+	var vary []string
+	for _, v := range header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if field = strings.TrimSpace(field); field != "" && !strings.EqualFold(field, "Accept-Encoding") {
+				vary = append(vary, field)
+			}
+		}
+	}
+	header.Del("Vary")
+	if len(vary) > 0 {
+		header.Set("Vary", strings.Join(vary, ", "))
+	}
+
+	return header
+}
+
+// writeIdempotencyError as a problem for JSON requests and requests with an Idempotency-Key header, and as an error page otherwise.
+func writeIdempotencyError(w http.ResponseWriter, r *http.Request, page html.PageFunc, code int, detail string) {
+	if isJSONRequest(r) || r.Header.Get("Idempotency-Key") != "" {
+		writeProblem(w, code, detail)
+		return
+	}
+	writeErrorPage(w, page, code, detail)
+}
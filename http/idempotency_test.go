@@ -0,0 +1,156 @@
+package http_test
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type mockIdempotencyStore struct {
+	hashes    map[string]string
+	responses map[string]model.IdempotentResponse
+}
+
+func (m *mockIdempotencyStore) StartIdempotentRequest(ctx context.Context, key, requestHash string, ttl time.Duration) (*model.IdempotentResponse, error) {
+	hash, ok := m.hashes[key]
+	if !ok {
+		m.hashes[key] = requestHash
+		return nil, nil
+	}
+	if hash != requestHash {
+		return nil, model.ErrorIdempotencyKeyRequestMismatch
+	}
+	resp, ok := m.responses[key]
+	if !ok {
+		return nil, model.ErrorIdempotencyKeyInProgress
+	}
+	return &resp, nil
+}
+
+func (m *mockIdempotencyStore) CompleteIdempotentRequest(ctx context.Context, key string, resp model.IdempotentResponse) error {
+	m.responses[key] = resp
+	return nil
+}
+
+func (m *mockIdempotencyStore) CancelIdempotentRequest(ctx context.Context, key string) error {
+	delete(m.hashes, key)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	newMux := func(middlewares ...func(http.Handler) http.Handler) (*chi.Mux, *int) {
+		store := &mockIdempotencyStore{hashes: map[string]string{}, responses: map[string]model.IdempotentResponse{}}
+
+		var calls int
+		mux := chi.NewMux()
+		mux.Use(middlewares...)
+		mux.Use(gluehttp.Idempotency(gluehttp.IdempotencyOptions{Store: store}))
+		mux.Post("/things", func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			_ = r.ParseForm()
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, "thing %v named %v", calls, r.Form.Get("name"))
+		})
+		mux.Post("/fail", func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		return mux, &calls
+	}
+
+	post := func(mux *chi.Mux, path, key, body string, headers ...string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("replays the first response for repeated requests with the same header key", func(t *testing.T) {
+		mux, calls := newMux()
+
+		rec := post(mux, "/things", "abc", "name=Thing")
+		is.Equal(t, http.StatusCreated, rec.Code)
+		is.Equal(t, "thing 1 named Thing", rec.Body.String())
+
+		rec = post(mux, "/things", "abc", "name=Thing")
+		is.Equal(t, http.StatusCreated, rec.Code)
+		is.Equal(t, "thing 1 named Thing", rec.Body.String())
+		is.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+		is.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+		is.Equal(t, 1, *calls)
+	})
+
+	t.Run("uses the key from the form field, and keeps the body readable", func(t *testing.T) {
+		mux, calls := newMux()
+
+		post(mux, "/things", "", "name=Thing&idempotency_key=abc")
+		rec := post(mux, "/things", "", "name=Thing&idempotency_key=abc")
+		is.Equal(t, "thing 1 named Thing", rec.Body.String())
+		is.Equal(t, 1, *calls)
+	})
+
+	t.Run("handles requests without a key every time", func(t *testing.T) {
+		mux, calls := newMux()
+
+		post(mux, "/things", "", "name=Thing")
+		post(mux, "/things", "", "name=Thing")
+		is.Equal(t, 2, *calls)
+	})
+
+	t.Run("rejects reuse of a key with a different request body", func(t *testing.T) {
+		mux, calls := newMux()
+
+		post(mux, "/things", "abc", "name=Thing")
+		rec := post(mux, "/things", "abc", "name=Other")
+		is.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		is.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		is.Equal(t, 1, *calls)
+	})
+
+	t.Run("does not store server errors, so requests can be retried", func(t *testing.T) {
+		mux, calls := newMux()
+
+		is.Equal(t, http.StatusInternalServerError, post(mux, "/fail", "abc", "").Code)
+		is.Equal(t, http.StatusInternalServerError, post(mux, "/fail", "abc", "").Code)
+		is.Equal(t, 2, *calls)
+	})
+
+	t.Run("replays compressed responses compressed again", func(t *testing.T) {
+		mux, calls := newMux(middleware.Compress(5, "text/plain"))
+
+		for range 2 {
+			rec := post(mux, "/things", "abc", "name=Thing", "Accept-Encoding", "gzip")
+			is.Equal(t, http.StatusCreated, rec.Code)
+			is.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			is.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+			r, err := gzip.NewReader(rec.Body)
+			is.NotError(t, err)
+			body, err := io.ReadAll(r)
+			is.NotError(t, err)
+			is.Equal(t, "thing 1 named Thing", string(body))
+		}
+		is.Equal(t, 1, *calls)
+	})
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	g "maragu.dev/gomponents"
+
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+const contextRealUserIDKey = ContextKey("realUserID")
+
+// Session keys for an ongoing impersonation.
+const (
+	sessionImpersonatedUserIDKey = "impersonatedUserID"
+	sessionImpersonationIDKey    = "impersonationID"
+)
+
+type impersonationStore interface {
+	StartImpersonation(ctx context.Context, adminUserID, userID model.UserID) (model.ImpersonationID, error)
+	StopImpersonation(ctx context.Context, id model.ImpersonationID) error
+}
+
+type sessionGetPutRemover interface {
+	GetString(ctx context.Context, key string) string
+	Put(ctx context.Context, key string, val any)
+	Remove(ctx context.Context, key string)
+}
+
+type ImpersonationOptions struct {
+	Log *slog.Logger
+
+	// Permission the admin needs to impersonate users. It's checked on every request, so revoking it stops ongoing impersonations.
+	Permission model.Permission
+
+	// PermissionsGetter to check the [ImpersonationOptions.Permission] of the admin with.
+	PermissionsGetter permissionsGetter
+
+	// Session to store the impersonation in. Defaults to the [Router] session manager.
+	Session sessionGetPutRemover
+
+	// Store for the audit records of impersonations, such as [maragu.dev/glue/sql.Helper].
+	Store impersonationStore
+
+	// Users to check that impersonated users exist and are active, when starting and on every request.
+	Users userActiveChecker
+}
+
+// Impersonate is [Middleware] for admins to see the app as another user, started with the routes from [Impersonation].
+// It must come after [Authenticate] and [TrackSessions], and before [SavePermissionsInContext].
+// While impersonating, [GetUserIDFromContext] returns the impersonated user ID, [GetRealUserIDFromContext] returns the admin ID,
+// and [IsImpersonating] returns true. Both IDs are added to the root span.
+// If the admin no longer has the permission, or the impersonated user is no longer active, the impersonation stops.
+func Impersonate(opts ImpersonationOptions) Middleware {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	log := opts.Log
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			adminUserID := GetUserIDFromContext(ctx)
+			if adminUserID == nil || GetAccessTokenFromContext(ctx) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID := model.UserID(opts.Session.GetString(ctx, sessionImpersonatedUserIDKey))
+			if userID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			permissions, err := opts.PermissionsGetter.GetPermissions(ctx, *adminUserID)
+			if err != nil {
+				log.ErrorContext(ctx, "Error getting permissions", "error", err, "userID", adminUserID)
+				http.Error(w, "error getting permissions", http.StatusInternalServerError)
+				return
+			}
+
+			if !slices.Contains(permissions, opts.Permission) {
+				log.InfoContext(ctx, "Stopping impersonation without permission", "adminUserID", adminUserID, "userID", userID)
+				if err := stopImpersonation(ctx, opts); err != nil {
+					log.ErrorContext(ctx, "Error stopping impersonation", "error", err, "adminUserID", adminUserID, "userID", userID)
+					http.Error(w, "error stopping impersonation", http.StatusInternalServerError)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			active, err := opts.Users.IsUserActive(ctx, userID)
+			if err != nil && !errors.Is(err, model.ErrorUserNotFound) {
+				log.ErrorContext(ctx, "Error checking if user is active", "error", err, "userID", userID)
+				http.Error(w, "error checking user", http.StatusInternalServerError)
+				return
+			}
+
+			if !active {
+				log.InfoContext(ctx, "Stopping impersonation of inactive user", "adminUserID", adminUserID, "userID", userID)
+				if err := stopImpersonation(ctx, opts); err != nil {
+					log.ErrorContext(ctx, "Error stopping impersonation", "error", err, "adminUserID", adminUserID, "userID", userID)
+					http.Error(w, "error stopping impersonation", http.StatusInternalServerError)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Add both user IDs to the root span
+			if rootSpan := GetRootSpanFromContext(ctx); rootSpan != nil && rootSpan.IsRecording() {
+				rootSpan.SetAttributes(
+					semconv.EnduserPseudoID(string(userID)),
+					attribute.String("enduser.impersonator.pseudo.id", string(*adminUserID)),
+				)
+			}
+
+			ctx = context.WithValue(ctx, contextRealUserIDKey, adminUserID)
+			ctx = withUserID(ctx, &userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// stopImpersonation by recording it in the audit trail and removing it from the session.
+func stopImpersonation(ctx context.Context, opts ImpersonationOptions) error {
+	if id := model.ImpersonationID(opts.Session.GetString(ctx, sessionImpersonationIDKey)); id != "" {
+		if err := opts.Store.StopImpersonation(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	opts.Session.Remove(ctx, sessionImpersonatedUserIDKey)
+	opts.Session.Remove(ctx, sessionImpersonationIDKey)
+	return nil
+}
+
+// GetRealUserIDFromContext, which is the admin ID while impersonating a user, see [Impersonate].
+// Otherwise, it's the same as [GetUserIDFromContext].
+func GetRealUserIDFromContext(ctx context.Context) *model.UserID {
+	if id, ok := ctx.Value(contextRealUserIDKey).(*model.UserID); ok {
+		return id
+	}
+	return GetUserIDFromContext(ctx)
+}
+
+// IsImpersonating returns whether the user ID in the context is a user impersonated by an admin, see [Impersonate].
+func IsImpersonating(ctx context.Context) bool {
+	return ctx.Value(contextRealUserIDKey) != nil
+}
+
+// Impersonation registers routes for admins to start and stop impersonating users, used with [Impersonate]:
+//   - POST /admin/users/{id}/impersonate starts impersonating the user, and redirects to the front page.
+//   - POST /impersonate/stop stops impersonating, and redirects to the front page.
+//
+// Starting requires the [ImpersonationOptions.Permission], and each impersonation is recorded in the [ImpersonationOptions.Store].
+// Users who also have the permission can't be impersonated.
+func Impersonation(r *Router, opts ImpersonationOptions) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	if opts.Session == nil {
+		opts.Session = r.SM
+	}
+
+	log := opts.Log
+
+	r.Group(func(r *Router) {
+		r.Use(Authorize(log, opts.PermissionsGetter, opts.Permission))
+
+		r.Post("/admin/users/{id}/impersonate", func(props html.PageProps) (g.Node, error) {
+			if props.Impersonating || GetAccessTokenFromContext(props.Ctx) != nil {
+				return nil, Error{Code: http.StatusForbidden, Err: errors.New("can only impersonate when logged in with a session")}
+			}
+
+			adminUserID := *props.UserID
+			userID := model.UserID(GetPathParam(props.R, "id"))
+			if userID == adminUserID {
+				return nil, Error{Code: http.StatusBadRequest, Err: errors.New("cannot impersonate yourself")}
+			}
+
+			active, err := opts.Users.IsUserActive(props.Ctx, userID)
+			if err != nil {
+				if errors.Is(err, model.ErrorUserNotFound) {
+					return nil, Error{Code: http.StatusNotFound, Err: err}
+				}
+				log.ErrorContext(props.Ctx, "Error checking user", "error", err, "userID", userID)
+				return nil, err
+			}
+			if !active {
+				return nil, Error{Code: http.StatusBadRequest, Err: model.ErrorUserInactive}
+			}
+
+			// Admins can't impersonate each other, so impersonating can't be used to act as another admin
+			permissions, err := opts.PermissionsGetter.GetPermissions(props.Ctx, userID)
+			if err != nil {
+				log.ErrorContext(props.Ctx, "Error getting permissions", "error", err, "userID", userID)
+				return nil, err
+			}
+			if slices.Contains(permissions, opts.Permission) {
+				return nil, Error{Code: http.StatusForbidden, Err: errors.New("cannot impersonate users with the permission to impersonate")}
+			}
+
+			id, err := opts.Store.StartImpersonation(props.Ctx, adminUserID, userID)
+			if err != nil {
+				log.ErrorContext(props.Ctx, "Error starting impersonation", "error", err, "adminUserID", adminUserID, "userID", userID)
+				return nil, err
+			}
+
+			opts.Session.Put(props.Ctx, sessionImpersonatedUserIDKey, userID.String())
+			opts.Session.Put(props.Ctx, sessionImpersonationIDKey, id.String())
+
+			log.InfoContext(props.Ctx, "Started impersonation", "adminUserID", adminUserID, "userID", userID, "impersonationID", id)
+
+			http.Redirect(props.W, props.R, "/", http.StatusFound)
+			return nil, nil
+		})
+	})
+
+	// Stopping is outside the Authorize group, because the impersonated user usually doesn't have the permission
+	r.Post("/impersonate/stop", func(props html.PageProps) (g.Node, error) {
+		if props.Impersonating {
+			if err := stopImpersonation(props.Ctx, opts); err != nil {
+				log.ErrorContext(props.Ctx, "Error stopping impersonation", "error", err, "userID", props.UserID)
+				return nil, err
+			}
+
+			log.InfoContext(props.Ctx, "Stopped impersonation", "adminUserID", GetRealUserIDFromContext(props.Ctx), "userID", props.UserID)
+		}
+
+		http.Redirect(props.W, props.R, "/", http.StatusFound)
+		return nil, nil
+	})
+}
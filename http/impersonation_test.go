@@ -0,0 +1,163 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type impersonationRecord struct {
+	adminUserID model.UserID
+	userID      model.UserID
+	stopped     bool
+}
+
+type mockImpersonationStore struct {
+	records []impersonationRecord
+}
+
+func (m *mockImpersonationStore) StartImpersonation(ctx context.Context, adminUserID, userID model.UserID) (model.ImpersonationID, error) {
+	m.records = append(m.records, impersonationRecord{adminUserID: adminUserID, userID: userID})
+	return model.ImpersonationID(fmt.Sprint("imp_", len(m.records)-1)), nil
+}
+
+func (m *mockImpersonationStore) StopImpersonation(ctx context.Context, id model.ImpersonationID) error {
+	var i int
+	_, _ = fmt.Sscanf(id.String(), "imp_%d", &i)
+	m.records[i].stopped = true
+	return nil
+}
+
+func TestImpersonation(t *testing.T) {
+	t.Run("starts and stops impersonating a user with an audit record", func(t *testing.T) {
+		client, store, _, _ := newImpersonationServer(t)
+
+		client.get(t, "/login-as?id=u_123")
+
+		res := client.postForm(t, "/admin/users/u_456/impersonate", nil)
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.Equal(t, 1, len(store.records))
+		is.Equal(t, model.UserID("u_123"), store.records[0].adminUserID)
+		is.Equal(t, model.UserID("u_456"), store.records[0].userID)
+
+		res = client.get(t, "/whoami")
+		is.Equal(t, "u_456 u_123 true [read]", readBody(t, res))
+
+		res = client.postForm(t, "/impersonate/stop", nil)
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.True(t, store.records[0].stopped)
+
+		res = client.get(t, "/whoami")
+		is.Equal(t, "u_123 u_123 false [manage_roles read write]", readBody(t, res))
+	})
+
+	t.Run("forbids users without the permission", func(t *testing.T) {
+		client, store, _, _ := newImpersonationServer(t)
+
+		client.get(t, "/login-as?id=u_456")
+
+		res := client.postForm(t, "/admin/users/u_123/impersonate", nil)
+		is.Equal(t, http.StatusForbidden, res.StatusCode)
+		is.Equal(t, 0, len(store.records))
+	})
+
+	t.Run("stops impersonating if the admin loses the permission", func(t *testing.T) {
+		client, store, roles, _ := newImpersonationServer(t)
+
+		client.get(t, "/login-as?id=u_123")
+		client.postForm(t, "/admin/users/u_456/impersonate", nil)
+
+		roles.roles["u_123"] = nil
+
+		res := client.get(t, "/whoami")
+		is.Equal(t, "u_123 u_123 false []", readBody(t, res))
+		is.True(t, store.records[0].stopped)
+	})
+
+	t.Run("stops impersonating if the user is deactivated", func(t *testing.T) {
+		client, store, _, users := newImpersonationServer(t)
+
+		client.get(t, "/login-as?id=u_123")
+		client.postForm(t, "/admin/users/u_456/impersonate", nil)
+
+		users.users[1].Active = false
+
+		res := client.get(t, "/whoami")
+		is.Equal(t, "u_123 u_123 false [manage_roles read write]", readBody(t, res))
+		is.True(t, store.records[0].stopped)
+	})
+
+	t.Run("forbids impersonating users with the permission", func(t *testing.T) {
+		client, store, roles, _ := newImpersonationServer(t)
+		roles.roles["u_456"] = []model.Role{"admin"}
+
+		client.get(t, "/login-as?id=u_123")
+
+		res := client.postForm(t, "/admin/users/u_456/impersonate", nil)
+		is.Equal(t, http.StatusForbidden, res.StatusCode)
+		is.Equal(t, 0, len(store.records))
+	})
+
+	t.Run("responds with not found for unknown users", func(t *testing.T) {
+		client, store, _, _ := newImpersonationServer(t)
+
+		client.get(t, "/login-as?id=u_123")
+
+		res := client.postForm(t, "/admin/users/u_789/impersonate", nil)
+		is.Equal(t, http.StatusNotFound, res.StatusCode)
+		is.Equal(t, 0, len(store.records))
+	})
+}
+
+func newImpersonationServer(t *testing.T) (*testClient, *mockImpersonationStore, *mockRoleStore, *mockUserStore) {
+	t.Helper()
+
+	log := slog.New(slog.DiscardHandler)
+	sm := scs.New()
+	store := &mockImpersonationStore{}
+	roles := &mockRoleStore{roles: map[model.UserID][]model.Role{"u_123": {"admin"}}}
+	pg := gluehttp.NewRolePermissionsGetter(gluehttp.NewRolePermissionsGetterOptions{
+		Roles: model.RolePermissions{"admin": {"read", "write", "manage_roles"}, "viewer": {"read"}},
+		Store: roles,
+	})
+	users := &mockUserStore{users: []model.User{
+		{ID: "u_123", Name: "Admin", Active: true},
+		{ID: "u_456", Name: "User", Active: true},
+	}}
+	roles.roles["u_456"] = []model.Role{"viewer"}
+
+	opts := gluehttp.ImpersonationOptions{
+		Log:               log,
+		Permission:        "manage_roles",
+		PermissionsGetter: pg,
+		Session:           sm,
+		Store:             store,
+		Users:             users,
+	}
+
+	router := gluehttp.NewRouter(gluehttp.NewRouterOpts{SM: sm})
+	router.Use(gluehttp.Authenticate(log, sm, users))
+	router.Use(gluehttp.Impersonate(opts))
+	router.Use(gluehttp.SavePermissionsInContext(log, pg))
+
+	gluehttp.Impersonation(router, opts)
+
+	router.Mux.Get("/login-as", func(w http.ResponseWriter, r *http.Request) {
+		sm.Put(r.Context(), gluehttp.SessionUserIDKey, r.URL.Query().Get("id"))
+	})
+
+	router.Mux.Get("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		props := gluehttp.GetProps(w, r)
+		_, _ = fmt.Fprintf(w, "%v %v %v %v", *props.UserID, *gluehttp.GetRealUserIDFromContext(r.Context()), props.Impersonating, props.Permissions)
+	})
+
+	return newTestClient(t, sm.LoadAndSave(router.Mux)), store, roles, users
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+// JSONHandler is a typed handler for JSON API routes, registered with [GetJSON], [PostJSON], [PutJSON], and [DeleteJSON].
+// The request body is decoded into Req, and the returned Resp is encoded as the response body.
+//
+// If Req or *Req has a Validate() error method, it's called after decoding, and an error results in 400 Bad Request.
+// If Req or *Req has a MaxSizeBytes() int64 method, it limits the request body size, which otherwise defaults to 1 MiB.
+// Larger bodies result in 413 Request Entity Too Large.
+// If Resp has a StatusCode() int method, it sets the response status code, which otherwise defaults to 200 OK.
+//
+// Errors are returned as RFC 9457 problem details, see [ProblemResponse]:
+//   - [Error] (an [httph.HTTPError]) responds with its status code.
+//   - [model.Error] responds with a matching status code, such as 404 for [model.ErrorUserNotFound].
+//   - An error rooted in [context.Canceled] responds with 499, like page routes.
+//   - Any other error responds with 500, without exposing the error message to the client.
+type JSONHandler[Req, Resp any] func(ctx context.Context, props html.PageProps, req Req) (Resp, error)
+
+// GetJSON registers a [JSONHandler] for GET requests on the router. Requests without a body leave Req as its zero value.
+func GetJSON[Req, Resp any](r *Router, path string, cb JSONHandler[Req, Resp]) {
+	r.Mux.Get(path, adaptJSON(cb))
+}
+
+// PostJSON registers a [JSONHandler] for POST requests on the router.
+func PostJSON[Req, Resp any](r *Router, path string, cb JSONHandler[Req, Resp]) {
+	r.Mux.Post(path, adaptJSON(cb))
+}
+
+// PutJSON registers a [JSONHandler] for PUT requests on the router.
+func PutJSON[Req, Resp any](r *Router, path string, cb JSONHandler[Req, Resp]) {
+	r.Mux.Put(path, adaptJSON(cb))
+}
+
+// DeleteJSON registers a [JSONHandler] for DELETE requests on the router.
+func DeleteJSON[Req, Resp any](r *Router, path string, cb JSONHandler[Req, Resp]) {
+	r.Mux.Delete(path, adaptJSON(cb))
+}
+
+// ProblemResponse is an RFC 9457 problem details response body, with content type application/problem+json.
+type ProblemResponse struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+const defaultMaxJSONBodySize = 1 << 20
+
+// modelErrorStatusCodes maps [model.Error]s to status codes. Unmapped errors are 400 Bad Request.
+var modelErrorStatusCodes = map[model.Error]int{
+	model.ErrorEmailConflict:                 http.StatusConflict,
+	model.ErrorIdempotencyKeyInProgress:      http.StatusConflict,
+	model.ErrorIdempotencyKeyRequestMismatch: http.StatusUnprocessableEntity,
+	model.ErrorSessionNotFound:               http.StatusNotFound,
+	model.ErrorTokenExpired:                  http.StatusGone,
+	model.ErrorTokenNotFound:                 http.StatusNotFound,
+	model.ErrorUserInactive:                  http.StatusForbidden,
+	model.ErrorUserNotFound:                  http.StatusNotFound,
+}
+
+type validator interface {
+	Validate() error
+}
+
+type maxSizeGiver interface {
+	MaxSizeBytes() int64
+}
+
+type statusCodeGiver interface {
+	StatusCode() int
+}
+
+// adaptJSON turns a [JSONHandler] into a [http.HandlerFunc].
+func adaptJSON[Req, Resp any](cb JSONHandler[Req, Resp]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+
+		r.Body = http.MaxBytesReader(w, r.Body, getMaxSize(&req))
+
+		// Only decode if there's a body, so GET and DELETE requests without one work
+		br := bufio.NewReader(r.Body)
+		if _, err := br.Peek(1); err == nil {
+			if err := json.NewDecoder(br).Decode(&req); err != nil {
+				writeRequestBodyProblem(w, "error decoding request body as JSON", err)
+				return
+			}
+		} else if !errors.Is(err, io.EOF) {
+			writeRequestBodyProblem(w, "error reading request body", err)
+			return
+		}
+
+		if err := validate(&req); err != nil {
+			writeProblem(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+
+		resp, err := cb(r.Context(), GetProps(w, r), req)
+		if err != nil {
+			code, detail := getProblem(err)
+			recordHandlerError(r.Context(), code, err)
+			writeProblem(w, code, detail)
+			return
+		}
+
+		writeJSONResponse(w, getStatusCode(resp), resp)
+	}
+}
+
+// writeRequestBodyProblem for an error reading or decoding the request body.
+// Bodies larger than the max size are 413 Request Entity Too Large, other errors are 400 Bad Request.
+func writeRequestBodyProblem(w http.ResponseWriter, detail string, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeProblem(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	writeProblem(w, http.StatusBadRequest, detail+": "+err.Error())
+}
+
+// getStatusCode from resp if it has a StatusCode() int method, otherwise 200 OK.
+func getStatusCode(resp any) int {
+	if v, ok := resp.(statusCodeGiver); ok {
+		return v.StatusCode()
+	}
+	return http.StatusOK
+}
+
+// writeJSONResponse with the given status code. 204 No Content has no body.
+func writeJSONResponse(w http.ResponseWriter, code int, resp any) {
+	if code == http.StatusNoContent {
+		w.WriteHeader(code)
+		return
+	}
+
+	// Encode to a buffer first, so encoding errors can still be reported with a status code
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(resp); err != nil {
+		writeProblem(w, http.StatusInternalServerError, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = io.Copy(w, &b)
+}
+
+// getMaxSize from req if it, or a pointer to it, has a MaxSizeBytes method, otherwise the default.
+func getMaxSize[Req any](req *Req) int64 {
+	if v, ok := any(*req).(maxSizeGiver); ok {
+		return v.MaxSizeBytes()
+	}
+	if v, ok := any(req).(maxSizeGiver); ok {
+		return v.MaxSizeBytes()
+	}
+	return defaultMaxJSONBodySize
+}
+
+// validate req if it, or a pointer to it, has a Validate method.
+func validate[Req any](req *Req) error {
+	if v, ok := any(*req).(validator); ok {
+		return v.Validate()
+	}
+	if v, ok := any(req).(validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// recordHandlerError on the span in the context, if it's a server error.
+func recordHandlerError(ctx context.Context, code int, err error) {
+	if code >= http.StatusInternalServerError {
+		span := trace.SpanFromContext(ctx)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "handler failed")
+	}
+}
+
+// getProblem status code and detail for an error returned from a [JSONHandler].
+func getProblem(err error) (int, string) {
+	if errors.Is(err, context.Canceled) {
+		return statusClientClosedRequest, ""
+	}
+
+	var httpErr Error
+	if errors.As(err, &httpErr) {
+		code := httpErr.StatusCode()
+		if httpErr.Err == nil || code >= http.StatusInternalServerError {
+			return code, ""
+		}
+		return code, httpErr.Err.Error()
+	}
+
+	var modelErr model.Error
+	if errors.As(err, &modelErr) {
+		if code, ok := modelErrorStatusCodes[modelErr]; ok {
+			return code, modelErr.Error()
+		}
+		return http.StatusBadRequest, modelErr.Error()
+	}
+
+	return http.StatusInternalServerError, ""
+}
+
+func writeProblem(w http.ResponseWriter, code int, detail string) {
+	title := http.StatusText(code)
+	if code == statusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(ProblemResponse{
+		Type:   "about:blank",
+		Title:  title,
+		Status: code,
+		Detail: detail,
+	})
+}
@@ -0,0 +1,173 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/html"
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+func (r greetRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type smallRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *smallRequest) MaxSizeBytes() int64 {
+	return 16
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+type createdResponse struct {
+	ID string `json:"id"`
+}
+
+func (createdResponse) StatusCode() int {
+	return http.StatusCreated
+}
+
+func TestPostJSON(t *testing.T) {
+	t.Run("decodes the request and encodes the response", func(t *testing.T) {
+		router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+		gluehttp.PostJSON(router, "/greet", func(ctx context.Context, props html.PageProps, req greetRequest) (greetResponse, error) {
+			return greetResponse{Greeting: "Hi " + req.Name}, nil
+		})
+
+		rec := serveJSON(t, router, http.MethodPost, "/greet", `{"name":"you"}`)
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var resp greetResponse
+		is.NotError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		is.Equal(t, "Hi you", resp.Greeting)
+	})
+
+	t.Run("uses the status code from the response", func(t *testing.T) {
+		router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+		gluehttp.PostJSON(router, "/things", func(ctx context.Context, props html.PageProps, req struct{}) (createdResponse, error) {
+			return createdResponse{ID: "t_123"}, nil
+		})
+
+		rec := serveJSON(t, router, http.MethodPost, "/things", `{}`)
+		is.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("responds with a 400 problem for invalid JSON", func(t *testing.T) {
+		router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+		gluehttp.PostJSON(router, "/greet", func(ctx context.Context, props html.PageProps, req greetRequest) (greetResponse, error) {
+			panic("not called")
+		})
+
+		rec := serveJSON(t, router, http.MethodPost, "/greet", `{`)
+		p := getProblem(t, rec)
+		is.Equal(t, http.StatusBadRequest, p.Status)
+		is.True(t, strings.HasPrefix(p.Detail, "error decoding request body as JSON"))
+	})
+
+	t.Run("responds with a 400 problem if validation fails", func(t *testing.T) {
+		router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+		gluehttp.PostJSON(router, "/greet", func(ctx context.Context, props html.PageProps, req greetRequest) (greetResponse, error) {
+			panic("not called")
+		})
+
+		rec := serveJSON(t, router, http.MethodPost, "/greet", `{"name":""}`)
+		p := getProblem(t, rec)
+		is.Equal(t, http.StatusBadRequest, p.Status)
+		is.Equal(t, "invalid request body: name is required", p.Detail)
+	})
+
+	t.Run("limits the request body size with a MaxSizeBytes method on a pointer receiver", func(t *testing.T) {
+		router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+		gluehttp.PostJSON(router, "/greet", func(ctx context.Context, props html.PageProps, req smallRequest) (greetResponse, error) {
+			return greetResponse{Greeting: "Hi " + req.Name}, nil
+		})
+
+		rec := serveJSON(t, router, http.MethodPost, "/greet", `{"name":"you"}`)
+		is.Equal(t, http.StatusOK, rec.Code)
+
+		rec = serveJSON(t, router, http.MethodPost, "/greet", `{"name":"you and everyone else"}`)
+		p := getProblem(t, rec)
+		is.Equal(t, http.StatusRequestEntityTooLarge, p.Status)
+		is.True(t, strings.Contains(p.Detail, "request body too large"))
+	})
+}
+
+func TestGetJSON(t *testing.T) {
+	t.Run("leaves the request as the zero value without a body", func(t *testing.T) {
+		router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+		gluehttp.GetJSON(router, "/things/{id}", func(ctx context.Context, props html.PageProps, req struct{}) (createdResponse, error) {
+			return createdResponse{ID: gluehttp.GetPathParam(props.R, "id")}, nil
+		})
+
+		rec := serveJSON(t, router, http.MethodGet, "/things/t_123", "")
+		is.Equal(t, http.StatusCreated, rec.Code)
+		is.Equal(t, `{"id":"t_123"}`+"\n", rec.Body.String())
+	})
+
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		detail string
+	}{
+		{"maps an http error to its status code", gluehttp.Error{Code: http.StatusTeapot, Err: errors.New("short and stout")}, http.StatusTeapot, "short and stout"},
+		{"maps a wrapped model error to a matching status code", fmt.Errorf("getting user: %w", model.ErrorUserNotFound), http.StatusNotFound, "user not found"},
+		{"maps an unknown model error to 400", model.Error("bad vibes"), http.StatusBadRequest, "bad vibes"},
+		{"maps a canceled context to 499", fmt.Errorf("querying: %w", context.Canceled), 499, ""},
+		{"maps other errors to 500 without exposing them", errors.New("secret database details"), http.StatusInternalServerError, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := gluehttp.NewRouter(gluehttp.NewRouterOpts{})
+			gluehttp.GetJSON(router, "/", func(ctx context.Context, props html.PageProps, req struct{}) (greetResponse, error) {
+				return greetResponse{}, test.err
+			})
+
+			rec := serveJSON(t, router, http.MethodGet, "/", "")
+			p := getProblem(t, rec)
+			is.Equal(t, test.code, p.Status)
+			is.Equal(t, test.detail, p.Detail)
+			is.Equal(t, "about:blank", p.Type)
+		})
+	}
+}
+
+func serveJSON(t *testing.T, router *gluehttp.Router, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.Mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func getProblem(t *testing.T, rec *httptest.ResponseRecorder) gluehttp.ProblemResponse {
+	t.Helper()
+	is.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	var p gluehttp.ProblemResponse
+	is.NotError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	is.Equal(t, rec.Code, p.Status)
+	return p
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	g "maragu.dev/gomponents"
+
+	"maragu.dev/glue/email"
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+const sessionRedirectKey = "redirect"
+
+type loginTokenStore interface {
+	CreateLoginToken(ctx context.Context, userID model.UserID, lifetime time.Duration) (string, error)
+	ConsumeLoginToken(ctx context.Context, token string) (model.UserID, error)
+}
+
+type userGetterCreator interface {
+	userActiveChecker
+	// GetUserByEmail returns [model.ErrorUserNotFound] if there's no user with the email address.
+	GetUserByEmail(ctx context.Context, email model.EmailAddress) (model.User, error)
+	// CreateUser returns [model.ErrorEmailConflict] if there's already a user with the email address.
+	CreateUser(ctx context.Context, name string, email model.EmailAddress) (model.User, error)
+}
+
+type sessionPutPopRenewer interface {
+	Put(ctx context.Context, key string, val any)
+	PopString(ctx context.Context, key string) string
+	RenewToken(ctx context.Context) error
+}
+
+type MagicLinkOptions struct {
+	Log *slog.Logger
+
+	// Page to render the login, signup, and other pages in.
+	Page html.PageFunc
+
+	// Sender for the login and signup emails, using the login and signup templates from [email.GetTemplates].
+	Sender email.Sender
+
+	// Session to log the user in to. Defaults to the [Router] session manager.
+	Session sessionPutPopRenewer
+
+	// Tokens stores login tokens, such as [maragu.dev/glue/sql.Helper].
+	Tokens loginTokenStore
+
+	// TokenLifetime is how long login links are valid for. Defaults to 15 minutes.
+	TokenLifetime time.Duration
+
+	// Users are looked up and created in the app's user store.
+	Users userGetterCreator
+}
+
+// MagicLink registers routes for passwordless signup and login with links sent by email:
+//   - GET /signup shows a signup form, and POST /signup creates the user and sends a signup email.
+//   - GET /login shows a login form, and POST /login sends a login email.
+//   - GET /login?token= logs the user in by storing the user ID at [SessionUserIDKey] in the session,
+//     and redirects to where the user came from, or /.
+//
+// So the forms don't reveal which email addresses have accounts, they always say that an email has been sent,
+// and signing up with an existing email address sends a login email instead, unless the user is inactive.
+func MagicLink(r *Router, opts MagicLinkOptions) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	if opts.Session == nil {
+		opts.Session = r.SM
+	}
+
+	if opts.TokenLifetime == 0 {
+		opts.TokenLifetime = 15 * time.Minute
+	}
+
+	log := opts.Log
+
+	r.Get("/signup", func(props html.PageProps) (g.Node, error) {
+		return html.SignupPage(opts.Page), nil
+	})
+
+	r.Post("/signup", func(props html.PageProps) (g.Node, error) {
+		name := strings.TrimSpace(props.R.FormValue("name"))
+		address := model.EmailAddress(props.R.FormValue("email")).ToLower()
+		if name == "" || !address.IsValid() {
+			props.W.WriteHeader(http.StatusBadRequest)
+			return html.SignupPage(opts.Page), nil
+		}
+
+		template := "signup"
+		user, err := opts.Users.CreateUser(props.Ctx, name, address)
+		if err != nil {
+			if !errors.Is(err, model.ErrorEmailConflict) {
+				log.ErrorContext(props.Ctx, "Error creating user", "error", err)
+				return html.ErrorPage(opts.Page), err
+			}
+
+			template = "login"
+			user, err = opts.Users.GetUserByEmail(props.Ctx, address)
+			if err != nil {
+				log.ErrorContext(props.Ctx, "Error getting user", "error", err)
+				return html.ErrorPage(opts.Page), err
+			}
+
+			// Like for POST /login, inactive users don't get a login link
+			if !user.Active {
+				return html.CheckEmailPage(opts.Page, address), nil
+			}
+		}
+
+		if err := sendLoginLink(props.Ctx, opts, user, template); err != nil {
+			log.ErrorContext(props.Ctx, "Error sending login link", "error", err, "userID", user.ID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		return html.CheckEmailPage(opts.Page, address), nil
+	})
+
+	r.Get("/login", func(props html.PageProps) (g.Node, error) {
+		token := props.R.URL.Query().Get("token")
+		if token == "" {
+			return html.LoginPage(opts.Page, getSafeRedirect(props.R.URL.Query().Get("redirect"))), nil
+		}
+
+		userID, err := opts.Tokens.ConsumeLoginToken(props.Ctx, token)
+		if err != nil {
+			if errors.Is(err, model.ErrorTokenNotFound) || errors.Is(err, model.ErrorTokenExpired) {
+				props.W.WriteHeader(http.StatusBadRequest)
+				return html.InvalidLinkPage(opts.Page), nil
+			}
+			log.ErrorContext(props.Ctx, "Error consuming login token", "error", err)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		active, err := opts.Users.IsUserActive(props.Ctx, userID)
+		if err != nil && !errors.Is(err, model.ErrorUserNotFound) {
+			log.ErrorContext(props.Ctx, "Error checking if user is active", "error", err, "userID", userID)
+			return html.ErrorPage(opts.Page), err
+		}
+		if !active {
+			props.W.WriteHeader(http.StatusBadRequest)
+			return html.InvalidLinkPage(opts.Page), nil
+		}
+
+		// Renew the session token on login, to prevent session fixation
+		if err := opts.Session.RenewToken(props.Ctx); err != nil {
+			log.ErrorContext(props.Ctx, "Error renewing session token", "error", err)
+			return html.ErrorPage(opts.Page), err
+		}
+		opts.Session.Put(props.Ctx, SessionUserIDKey, userID.String())
+
+		redirect := getSafeRedirect(opts.Session.PopString(props.Ctx, sessionRedirectKey))
+		if redirect == "" {
+			redirect = "/"
+		}
+
+		log.InfoContext(props.Ctx, "Logged in", "userID", userID)
+		http.Redirect(props.W, props.R, redirect, http.StatusFound)
+		return nil, nil
+	})
+
+	r.Post("/login", func(props html.PageProps) (g.Node, error) {
+		address := model.EmailAddress(props.R.FormValue("email")).ToLower()
+		redirect := getSafeRedirect(props.R.FormValue("redirect"))
+		if !address.IsValid() {
+			props.W.WriteHeader(http.StatusBadRequest)
+			return html.LoginPage(opts.Page, redirect), nil
+		}
+
+		// Remember where to go after login, for when the link is opened in the same browser
+		if redirect != "" {
+			opts.Session.Put(props.Ctx, sessionRedirectKey, redirect)
+		}
+
+		user, err := opts.Users.GetUserByEmail(props.Ctx, address)
+		if err != nil {
+			if errors.Is(err, model.ErrorUserNotFound) {
+				return html.CheckEmailPage(opts.Page, address), nil
+			}
+			log.ErrorContext(props.Ctx, "Error getting user", "error", err)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		if !user.Active {
+			return html.CheckEmailPage(opts.Page, address), nil
+		}
+
+		if err := sendLoginLink(props.Ctx, opts, user, "login"); err != nil {
+			log.ErrorContext(props.Ctx, "Error sending login link", "error", err, "userID", user.ID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		return html.CheckEmailPage(opts.Page, address), nil
+	})
+}
+
+// sendLoginLink to the user with a new login token, using the given email template.
+func sendLoginLink(ctx context.Context, opts MagicLinkOptions, user model.User, template string) error {
+	token, err := opts.Tokens.CreateLoginToken(ctx, user.ID, opts.TokenLifetime)
+	if err != nil {
+		return err
+	}
+
+	subject, preheader := "Log in", "Click the link to log in."
+	if template == "signup" {
+		subject, preheader = "Welcome!", "Click the link to log in to your new account."
+	}
+
+	return opts.Sender.SendTransactional(ctx, user.Name, user.Email, subject, preheader, template, model.Keywords{
+		"name":  user.Name,
+		"token": token,
+	})
+}
+
+// getSafeRedirect returns the redirect if it's a local path, or the empty string otherwise,
+// so login links can't be used to redirect to other sites.
+func getSafeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return ""
+	}
+	return redirect
+}
@@ -0,0 +1,272 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type mockTokenStore struct {
+	tokens map[string]model.UserID
+}
+
+func (m *mockTokenStore) CreateLoginToken(ctx context.Context, userID model.UserID, lifetime time.Duration) (string, error) {
+	token := "t_" + string(userID)
+	m.tokens[token] = userID
+	return token, nil
+}
+
+func (m *mockTokenStore) ConsumeLoginToken(ctx context.Context, token string) (model.UserID, error) {
+	userID, ok := m.tokens[token]
+	if !ok {
+		return "", model.ErrorTokenNotFound
+	}
+	delete(m.tokens, token)
+	return userID, nil
+}
+
+type mockUserStore struct {
+	users []model.User
+}
+
+func (m *mockUserStore) IsUserActive(ctx context.Context, id model.UserID) (bool, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u.Active, nil
+		}
+	}
+	return false, model.ErrorUserNotFound
+}
+
+func (m *mockUserStore) GetUser(ctx context.Context, id model.UserID) (model.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return model.User{}, model.ErrorUserNotFound
+}
+
+func (m *mockUserStore) GetUserByEmail(ctx context.Context, email model.EmailAddress) (model.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return model.User{}, model.ErrorUserNotFound
+}
+
+func (m *mockUserStore) CreateUser(ctx context.Context, name string, email model.EmailAddress) (model.User, error) {
+	if _, err := m.GetUserByEmail(ctx, email); err == nil {
+		return model.User{}, model.ErrorEmailConflict
+	}
+	u := model.User{ID: model.UserID("u_" + name), Name: name, Email: email, Active: true}
+	m.users = append(m.users, u)
+	return u, nil
+}
+
+type sentEmail struct {
+	email    model.EmailAddress
+	template string
+	kw       model.Keywords
+}
+
+type mockSender struct {
+	sent []sentEmail
+}
+
+func (m *mockSender) SendTransactional(ctx context.Context, name string, email model.EmailAddress, subject, preheader, template string, kw model.Keywords) error {
+	m.sent = append(m.sent, sentEmail{email: email, template: template, kw: kw})
+	return nil
+}
+
+func TestMagicLink(t *testing.T) {
+	t.Run("signs up, sends a signup email, and logs in with the link", func(t *testing.T) {
+		client, sender, _ := newMagicLinkServer(t)
+
+		res := client.postForm(t, "/signup", url.Values{"name": {"You"}, "email": {"You@example.com"}})
+		is.Equal(t, http.StatusOK, res.StatusCode)
+
+		is.Equal(t, 1, len(sender.sent))
+		is.Equal(t, model.EmailAddress("you@example.com"), sender.sent[0].email)
+		is.Equal(t, "signup", sender.sent[0].template)
+		is.Equal(t, "You", sender.sent[0].kw["name"])
+
+		res = client.get(t, "/login?token="+sender.sent[0].kw["token"])
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.Equal(t, "/", res.Header.Get("Location"))
+
+		res = client.get(t, "/whoami")
+		is.Equal(t, "u_You", readBody(t, res))
+	})
+
+	t.Run("sends a login email instead when signing up with an existing email address", func(t *testing.T) {
+		client, sender, users := newMagicLinkServer(t)
+		users.users = append(users.users, model.User{ID: "u_123", Name: "Me", Email: "me@example.com", Active: true})
+
+		res := client.postForm(t, "/signup", url.Values{"name": {"Me again"}, "email": {"me@example.com"}})
+		is.Equal(t, http.StatusOK, res.StatusCode)
+
+		is.Equal(t, 1, len(sender.sent))
+		is.Equal(t, "login", sender.sent[0].template)
+		is.Equal(t, "Me", sender.sent[0].kw["name"])
+	})
+
+	t.Run("does not send an email when signing up with the email address of an inactive user", func(t *testing.T) {
+		client, sender, users := newMagicLinkServer(t)
+		users.users = append(users.users, model.User{ID: "u_123", Name: "Me", Email: "me@example.com", Active: false})
+
+		res := client.postForm(t, "/signup", url.Values{"name": {"Me again"}, "email": {"me@example.com"}})
+		is.Equal(t, http.StatusOK, res.StatusCode)
+		is.True(t, strings.Contains(readBody(t, res), "Check your email"))
+
+		is.Equal(t, 0, len(sender.sent))
+	})
+
+	t.Run("sends a login email and redirects back after login", func(t *testing.T) {
+		client, sender, users := newMagicLinkServer(t)
+		users.users = append(users.users, model.User{ID: "u_123", Name: "Me", Email: "me@example.com", Active: true})
+
+		res := client.postForm(t, "/login", url.Values{"email": {"me@example.com"}, "redirect": {"/secret"}})
+		is.Equal(t, http.StatusOK, res.StatusCode)
+		is.Equal(t, 1, len(sender.sent))
+		is.Equal(t, "login", sender.sent[0].template)
+
+		res = client.get(t, "/login?token="+sender.sent[0].kw["token"])
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.Equal(t, "/secret", res.Header.Get("Location"))
+	})
+
+	t.Run("does not redirect to other sites after login", func(t *testing.T) {
+		client, sender, users := newMagicLinkServer(t)
+		users.users = append(users.users, model.User{ID: "u_123", Name: "Me", Email: "me@example.com", Active: true})
+
+		res := client.postForm(t, "/login", url.Values{"email": {"me@example.com"}, "redirect": {"//example.com"}})
+		is.Equal(t, http.StatusOK, res.StatusCode)
+
+		res = client.get(t, "/login?token="+sender.sent[0].kw["token"])
+		is.Equal(t, "/", res.Header.Get("Location"))
+	})
+
+	t.Run("does not send an email or reveal it for unknown or inactive users", func(t *testing.T) {
+		client, sender, users := newMagicLinkServer(t)
+		users.users = append(users.users, model.User{ID: "u_123", Name: "Me", Email: "me@example.com", Active: false})
+
+		for _, address := range []string{"me@example.com", "nobody@example.com"} {
+			res := client.postForm(t, "/login", url.Values{"email": {address}})
+			is.Equal(t, http.StatusOK, res.StatusCode)
+			is.True(t, strings.Contains(readBody(t, res), "Check your email"))
+		}
+
+		is.Equal(t, 0, len(sender.sent))
+	})
+
+	t.Run("shows an invalid link page for a used token", func(t *testing.T) {
+		client, sender, users := newMagicLinkServer(t)
+		users.users = append(users.users, model.User{ID: "u_123", Name: "Me", Email: "me@example.com", Active: true})
+
+		client.postForm(t, "/login", url.Values{"email": {"me@example.com"}})
+		token := sender.sent[0].kw["token"]
+
+		res := client.get(t, "/login?token="+token)
+		is.Equal(t, http.StatusFound, res.StatusCode)
+
+		res = client.get(t, "/login?token="+token)
+		is.Equal(t, http.StatusBadRequest, res.StatusCode)
+		is.True(t, strings.Contains(readBody(t, res), "Invalid link"))
+	})
+
+	t.Run("responds with bad request for an invalid email address", func(t *testing.T) {
+		client, sender, _ := newMagicLinkServer(t)
+
+		res := client.postForm(t, "/signup", url.Values{"name": {"You"}, "email": {"notanemail"}})
+		is.Equal(t, http.StatusBadRequest, res.StatusCode)
+		is.Equal(t, 0, len(sender.sent))
+	})
+}
+
+type testClient struct {
+	c       *http.Client
+	baseURL string
+}
+
+func (c *testClient) get(t *testing.T, path string) *http.Response {
+	t.Helper()
+	res, err := c.c.Get(c.baseURL + path)
+	is.NotError(t, err)
+	return res
+}
+
+func (c *testClient) postForm(t *testing.T, path string, values url.Values) *http.Response {
+	t.Helper()
+	res, err := c.c.PostForm(c.baseURL+path, values)
+	is.NotError(t, err)
+	return res
+}
+
+// newTestClient for a test server with h, keeping cookies between requests and not following redirects.
+func newTestClient(t *testing.T, h http.Handler) *testClient {
+	t.Helper()
+
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	jar, err := cookiejar.New(nil)
+	is.NotError(t, err)
+
+	return &testClient{
+		c: &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		baseURL: server.URL,
+	}
+}
+
+func newMagicLinkServer(t *testing.T) (*testClient, *mockSender, *mockUserStore) {
+	t.Helper()
+
+	sm := scs.New()
+	router := gluehttp.NewRouter(gluehttp.NewRouterOpts{SM: sm})
+	sender := &mockSender{}
+	users := &mockUserStore{}
+
+	gluehttp.MagicLink(router, gluehttp.MagicLinkOptions{
+		Log:    slog.New(slog.DiscardHandler),
+		Page:   testPage,
+		Sender: sender,
+		Tokens: &mockTokenStore{tokens: map[string]model.UserID{}},
+		Users:  users,
+	})
+
+	router.Mux.Get("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sm.GetString(r.Context(), gluehttp.SessionUserIDKey)))
+	})
+
+	return newTestClient(t, sm.LoadAndSave(router.Mux)), sender, users
+}
+
+func readBody(t *testing.T, res *http.Response) string {
+	t.Helper()
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	b, err := io.ReadAll(res.Body)
+	is.NotError(t, err)
+	return string(b)
+}
@@ -100,6 +100,11 @@ func OpenTelemetry(next http.Handler) http.Handler {
 			span.SetName(r.Method + " " + routePattern)
 			span.SetAttributes(semconv.HTTPRoute(routePattern))
 
+			// Request duration metrics are recorded by otelhttp, but only get the route through the labeler
+			if labeler, ok := otelhttp.LabelerFromContext(r.Context()); ok {
+				labeler.Add(semconv.HTTPRoute(routePattern))
+			}
+
 			// The idea of a "main" span is from "A Practitioner's Guide to Wide Events":
 			// https://jeremymorrell.dev/blog/a-practitioners-guide-to-wide-events/#:~:text=A%20convention%20to%20filter%20out%20everything%20else
 			span.SetAttributes(attribute.Bool("main", true))
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
 	"maragu.dev/is"
@@ -18,6 +19,26 @@ import (
 )
 
 func TestOpenTelemetry(t *testing.T) {
+	t.Run("records request duration with the route pattern", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+
+		mux := chi.NewMux()
+		mux.Use(gluehttp.OpenTelemetry)
+		mux.Get("/things/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+		req := httptest.NewRequest(http.MethodGet, "/things/42", nil)
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+
+		m := oteltest.GetMetric(t, r, "http.server.request.duration")
+		h, ok := m.Data.(metricdata.Histogram[float64])
+		is.True(t, ok)
+		is.Equal(t, 1, len(h.DataPoints))
+		is.Equal(t, uint64(1), h.DataPoints[0].Count)
+		route, ok := h.DataPoints[0].Attributes.Value(semconv.HTTPRouteKey)
+		is.True(t, ok)
+		is.Equal(t, "/things/{id}", route.AsString())
+	})
+
 	t.Run("sets span name to method and route pattern", func(t *testing.T) {
 		tests := []struct {
 			name         string
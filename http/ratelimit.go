@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+type rateLimiter interface {
+	// TakeRateLimitToken returns whether a token was taken, and if not, how long until the next token is available.
+	TakeRateLimitToken(ctx context.Context, key string, limit model.RateLimit) (bool, time.Duration, error)
+}
+
+type RateLimitOptions struct {
+	// Key to rate limit the request by, such as [RateLimitByIP], [RateLimitByUser], or [RateLimitByRoute].
+	// Requests with an empty key are not rate limited. Defaults to [RateLimitByIP].
+	Key func(r *http.Request) string
+
+	// Limit for each key.
+	Limit model.RateLimit
+
+	// Limiter to store the token buckets in, such as [MemoryRateLimiter] for a single process,
+	// or [maragu.dev/glue/sql.Helper] to share limits across processes.
+	Limiter rateLimiter
+
+	Log *slog.Logger
+
+	// Name of the limit, to keep buckets separate when using the same Limiter for different limits.
+	Name string
+
+	// Page to render the 429 Too Many Requests page in. If nil, the response is plain text.
+	Page html.PageFunc
+}
+
+// RateLimit is [Middleware] to limit the request rate with token buckets, see [model.RateLimit].
+// Throttled requests get 429 Too Many Requests with a Retry-After header, as a JSON [ProblemResponse] for JSON requests,
+// and the throttling is recorded on the root span.
+// If the Limiter returns an error, the error is logged and the request is allowed, so a failing store doesn't take the app down.
+func RateLimit(opts RateLimitOptions) Middleware {
+	if opts.Key == nil {
+		opts.Key = RateLimitByIP
+	}
+
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	log := opts.Log
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			key := opts.Key(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if opts.Name != "" {
+				key = opts.Name + ":" + key
+			}
+
+			ok, wait, err := opts.Limiter.TakeRateLimitToken(ctx, key, opts.Limit)
+			if err != nil {
+				log.ErrorContext(ctx, "Error taking rate limit token", "error", err, "key", key)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if rootSpan := GetRootSpanFromContext(ctx); rootSpan != nil && rootSpan.IsRecording() {
+				rootSpan.SetAttributes(
+					attribute.Bool("http.rate_limited", true),
+					attribute.String("http.rate_limit.key", key),
+					attribute.Float64("http.rate_limit.retry_after", wait.Seconds()),
+				)
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+
+			switch {
+			case isJSONRequest(r):
+				writeProblem(w, http.StatusTooManyRequests, "")
+			case opts.Page != nil:
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = html.TooManyRequestsPage(opts.Page).Render(w)
+			default:
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+			}
+		})
+	}
+}
+
+// RateLimitByIP keys requests by client IP. Use [middleware.RealIP] to get the client IP behind a proxy.
+func RateLimitByIP(r *http.Request) string {
+	return "ip:" + getIP(r)
+}
+
+// RateLimitByUser keys requests by user ID from [GetUserIDFromContext], or by IP for anonymous users.
+func RateLimitByUser(r *http.Request) string {
+	if userID := GetUserIDFromContext(r.Context()); userID != nil {
+		return "user:" + userID.String()
+	}
+	return RateLimitByIP(r)
+}
+
+// RateLimitByRoute keys requests by method and route pattern, so the limit is shared by everyone using the route.
+// The route pattern is only known when the middleware is used on routes, with [Router.Group] or [Router.Route].
+func RateLimitByRoute(r *http.Request) string {
+	var routePattern string
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		routePattern = rctx.RoutePattern()
+	}
+	return "route:" + r.Method + " " + routePattern
+}
+
+// MemoryRateLimiter keeps token buckets in memory, for [RateLimit] in a single process.
+type MemoryRateLimiter struct {
+	buckets     map[string]memoryBucket
+	lastCleanup time.Time
+	lock        sync.Mutex
+	now         func() time.Time
+}
+
+type memoryBucket struct {
+	limit   model.RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets:     map[string]memoryBucket{},
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// TakeRateLimitToken satisfies [rateLimiter].
+func (m *MemoryRateLimiter) TakeRateLimitToken(ctx context.Context, key string, limit model.RateLimit) (bool, time.Duration, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.now()
+	m.cleanup(now)
+
+	b, exists := m.buckets[key]
+	if !exists {
+		b = memoryBucket{tokens: float64(limit.Burst), updated: now}
+	}
+
+	tokens, ok, wait := limit.Take(b.tokens, b.updated, now)
+	if ok {
+		m.buckets[key] = memoryBucket{limit: limit, tokens: tokens, updated: now}
+	}
+	return ok, wait, nil
+}
+
+// cleanup buckets that have refilled completely, at most once a minute.
+// They can be deleted without changing any limits, since new buckets start full.
+func (m *MemoryRateLimiter) cleanup(now time.Time) {
+	if now.Sub(m.lastCleanup) < time.Minute {
+		return
+	}
+	m.lastCleanup = now
+
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) >= time.Duration(b.limit.Burst)*b.limit.Every {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+var _ rateLimiter = (*MemoryRateLimiter)(nil)
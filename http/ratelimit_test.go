@@ -0,0 +1,131 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/oteltest"
+)
+
+type erroringRateLimiter struct{}
+
+func (erroringRateLimiter) TakeRateLimitToken(ctx context.Context, key string, limit model.RateLimit) (bool, time.Duration, error) {
+	return false, 0, errors.New("oh no")
+}
+
+func TestRateLimit(t *testing.T) {
+
+	newMux := func(opts gluehttp.RateLimitOptions) *chi.Mux {
+		if opts.Limiter == nil {
+			opts.Limiter = gluehttp.NewMemoryRateLimiter()
+		}
+		opts.Limit = model.RateLimit{Burst: 2, Every: time.Hour}
+		opts.Page = testPage
+
+		mux := chi.NewMux()
+		mux.Use(gluehttp.OpenTelemetry)
+		mux.Group(func(r chi.Router) {
+			r.Use(gluehttp.RateLimit(opts))
+			r.Get("/things/{id}", func(w http.ResponseWriter, r *http.Request) {})
+		})
+		return mux
+	}
+
+	serve := func(mux *chi.Mux, path, ip string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("allows bursts, then responds with the too many requests page and Retry-After", func(t *testing.T) {
+		mux := newMux(gluehttp.RateLimitOptions{})
+
+		is.Equal(t, http.StatusOK, serve(mux, "/things/1", "1.2.3.4").Code)
+		is.Equal(t, http.StatusOK, serve(mux, "/things/1", "1.2.3.4").Code)
+
+		rec := serve(mux, "/things/1", "1.2.3.4")
+		is.Equal(t, http.StatusTooManyRequests, rec.Code)
+		is.Equal(t, "3600", rec.Header().Get("Retry-After"))
+		is.True(t, strings.Contains(rec.Body.String(), "Too many requests"))
+
+		is.Equal(t, http.StatusOK, serve(mux, "/things/1", "5.6.7.8").Code)
+	})
+
+	t.Run("responds with JSON for JSON requests", func(t *testing.T) {
+		mux := newMux(gluehttp.RateLimitOptions{})
+		serve(mux, "/things/1", "1.2.3.4")
+		serve(mux, "/things/1", "1.2.3.4")
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+		req.RemoteAddr = "1.2.3.4:1234"
+		req.Header.Set("Accept", "application/json")
+		mux.ServeHTTP(rec, req)
+
+		is.Equal(t, http.StatusTooManyRequests, rec.Code)
+		is.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("records throttling on the root span", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+		mux := newMux(gluehttp.RateLimitOptions{Name: "things"})
+		serve(mux, "/things/1", "1.2.3.4")
+		serve(mux, "/things/1", "1.2.3.4")
+		serve(mux, "/things/1", "1.2.3.4")
+
+		span := lastEndedSpan(t, sr)
+		is.True(t, oteltest.HasAttribute(span.Attributes(), attribute.Bool("http.rate_limited", true)))
+		is.True(t, oteltest.HasAttribute(span.Attributes(), attribute.String("http.rate_limit.key", "things:ip:1.2.3.4")))
+	})
+
+	t.Run("can key by route, shared by everyone", func(t *testing.T) {
+		mux := newMux(gluehttp.RateLimitOptions{Key: gluehttp.RateLimitByRoute})
+
+		is.Equal(t, http.StatusOK, serve(mux, "/things/1", "1.2.3.4").Code)
+		is.Equal(t, http.StatusOK, serve(mux, "/things/2", "5.6.7.8").Code)
+		is.Equal(t, http.StatusTooManyRequests, serve(mux, "/things/3", "9.10.11.12").Code)
+	})
+
+	t.Run("does not limit requests with an empty key", func(t *testing.T) {
+		mux := newMux(gluehttp.RateLimitOptions{Key: func(r *http.Request) string { return "" }})
+
+		for range 5 {
+			is.Equal(t, http.StatusOK, serve(mux, "/things/1", "1.2.3.4").Code)
+		}
+	})
+
+	t.Run("allows requests if the limiter errors", func(t *testing.T) {
+		mux := newMux(gluehttp.RateLimitOptions{Limiter: erroringRateLimiter{}})
+
+		is.Equal(t, http.StatusOK, serve(mux, "/things/1", "1.2.3.4").Code)
+	})
+}
+
+func TestRateLimitByUser(t *testing.T) {
+	t.Run("keys by user ID, falling back to IP", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "1.2.3.4:1234"
+		is.Equal(t, "ip:1.2.3.4", gluehttp.RateLimitByUser(req))
+
+		var key string
+		h := gluehttp.Authenticate(slog.New(slog.DiscardHandler), &mockSessionManager{exists: true}, &mockUserActiveChecker{active: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key = gluehttp.RateLimitByUser(r)
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		is.Equal(t, "user:u_123", key)
+	})
+}
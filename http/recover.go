@@ -0,0 +1,71 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/glue/html"
+)
+
+// Recover is [Middleware] that recovers from panics in later handlers.
+// The panic and stack are recorded on the root span and logged, so the log has the trace and span IDs.
+// The response is [html.ErrorPage] with status 500, or a JSON [ProblemResponse] for JSON requests,
+// unless the handler had already started writing the response.
+// A panic with [http.ErrAbortHandler] is not recovered, since it's used to abort a response on purpose.
+func Recover(log *slog.Logger, page html.PageFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				ctx := r.Context()
+				err := fmt.Errorf("panic: %v", rec)
+				stack := string(debug.Stack())
+
+				span := GetRootSpanFromContext(ctx)
+				if span == nil {
+					span = trace.SpanFromContext(ctx)
+				}
+				span.RecordError(err, trace.WithAttributes(semconv.ExceptionStacktrace(stack)))
+				span.SetStatus(codes.Error, "panic")
+
+				log.ErrorContext(ctx, "Recovered from panic", "error", err, "stack", stack)
+
+				if ww.Status() != 0 {
+					return
+				}
+
+				if isJSONRequest(r) {
+					writeProblem(ww, http.StatusInternalServerError, "")
+					return
+				}
+
+				if page == nil {
+					http.Error(ww, "internal server error", http.StatusInternalServerError)
+					return
+				}
+
+				ww.Header().Set("Content-Type", "text/html; charset=utf-8")
+				ww.WriteHeader(http.StatusInternalServerError)
+				_ = html.ErrorPage(page).Render(ww)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package http_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/codes"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/oteltest"
+)
+
+func TestRecover(t *testing.T) {
+
+	newMux := func() *chi.Mux {
+		mux := chi.NewMux()
+		mux.Use(gluehttp.OpenTelemetry, gluehttp.Recover(slog.New(slog.DiscardHandler), testPage))
+		mux.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("oh no")
+		})
+		mux.Get("/panic-after-write", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("oh no")
+		})
+		return mux
+	}
+
+	t.Run("renders the error page and records the panic on the root span", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		rec := httptest.NewRecorder()
+		newMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		is.Equal(t, http.StatusInternalServerError, rec.Code)
+		is.True(t, strings.Contains(rec.Body.String(), "Something went wrong"))
+
+		span := lastEndedSpan(t, sr)
+		is.Equal(t, codes.Error, span.Status().Code)
+		is.Equal(t, 1, len(span.Events()))
+		is.Equal(t, "exception", span.Events()[0].Name)
+		is.True(t, oteltest.HasAttributeKey(span.Events()[0].Attributes, "exception.stacktrace"))
+	})
+
+	t.Run("responds with JSON for JSON requests", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		req.Header.Set("Accept", "application/json")
+		newMux().ServeHTTP(rec, req)
+
+		is.Equal(t, http.StatusInternalServerError, rec.Code)
+		is.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("does not write a response if the handler already did", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic-after-write", nil))
+
+		is.Equal(t, http.StatusAccepted, rec.Code)
+		is.Equal(t, "", rec.Body.String())
+	})
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	g "maragu.dev/gomponents"
+
+	"maragu.dev/glue/html"
+)
+
+// Resource served both as an HTML page and as JSON from the same route, registered with [GetResource], [PostResource],
+// [PutResource], and [DeleteResource].
+//
+// The representation is JSON if the path has a .json extension, the request is authenticated with an access token,
+// or the Accept header accepts JSON but not HTML. Otherwise, it's HTML.
+// The representation is recorded on the span as http.response.representation.
+//
+// If Resp has a StatusCode() int method, it sets the response status code for both representations, which otherwise defaults to 200 OK.
+// Errors map to status codes like for [JSONHandler], as [ProblemResponse] for JSON and as an error page for HTML.
+type Resource[Resp any] struct {
+	// Handler gets the value of the resource.
+	Handler func(props html.PageProps) (Resp, error)
+
+	// HTML renders the value as HTML, usually in a page.
+	HTML func(props html.PageProps, resp Resp) g.Node
+
+	// Page to render HTML error pages in. If nil, HTML errors are plain text.
+	Page html.PageFunc
+}
+
+// GetResource registers a [Resource] for GET requests on the router, at the path and at the path with a .json extension.
+func GetResource[Resp any](r *Router, path string, res Resource[Resp]) {
+	for _, p := range getResourcePaths(path) {
+		r.Mux.Get(p, adaptResource(res))
+	}
+}
+
+// PostResource registers a [Resource] for POST requests on the router, at the path and at the path with a .json extension.
+func PostResource[Resp any](r *Router, path string, res Resource[Resp]) {
+	for _, p := range getResourcePaths(path) {
+		r.Mux.Post(p, adaptResource(res))
+	}
+}
+
+// PutResource registers a [Resource] for PUT requests on the router, at the path and at the path with a .json extension.
+func PutResource[Resp any](r *Router, path string, res Resource[Resp]) {
+	for _, p := range getResourcePaths(path) {
+		r.Mux.Put(p, adaptResource(res))
+	}
+}
+
+// DeleteResource registers a [Resource] for DELETE requests on the router, at the path and at the path with a .json extension.
+func DeleteResource[Resp any](r *Router, path string, res Resource[Resp]) {
+	for _, p := range getResourcePaths(path) {
+		r.Mux.Delete(p, adaptResource(res))
+	}
+}
+
+// getResourcePaths for the path, which is also registered with a .json extension if it doesn't end in a slash.
+func getResourcePaths(path string) []string {
+	if strings.HasSuffix(path, "/") {
+		return []string{path}
+	}
+	return []string{path, path + ".json"}
+}
+
+// adaptResource turns a [Resource] into a [http.HandlerFunc].
+func adaptResource[Resp any](res Resource[Resp]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		asJSON := strings.HasSuffix(r.URL.Path, ".json") || isJSONRequest(r)
+
+		representation := "html"
+		if asJSON {
+			representation = "json"
+		}
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.response.representation", representation))
+
+		w.Header().Add("Vary", "Accept")
+
+		props := GetProps(w, r)
+		resp, err := res.Handler(props)
+		if err != nil {
+			code, detail := getProblem(err)
+			recordHandlerError(r.Context(), code, err)
+
+			if asJSON {
+				writeProblem(w, code, detail)
+				return
+			}
+			writeErrorPage(w, res.Page, code, detail)
+			return
+		}
+
+		code := getStatusCode(resp)
+
+		if asJSON {
+			writeJSONResponse(w, code, resp)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(code)
+		if code == http.StatusNoContent {
+			return
+		}
+		_ = res.HTML(props, resp).Render(w)
+	}
+}
+
+// writeErrorPage for the status code, with the detail if there's no more specific page.
+func writeErrorPage(w http.ResponseWriter, page html.PageFunc, code int, detail string) {
+	if code == statusClientClosedRequest {
+		w.WriteHeader(code)
+		return
+	}
+
+	title := http.StatusText(code)
+
+	if page == nil {
+		if detail == "" {
+			detail = strings.ToLower(title)
+		}
+		http.Error(w, detail, code)
+		return
+	}
+
+	var n g.Node
+	switch {
+	case code == http.StatusNotFound:
+		n = html.NotFoundPage(page)
+	case code == http.StatusForbidden:
+		n = html.ForbiddenPage(page)
+	case code == http.StatusTooManyRequests:
+		n = html.TooManyRequestsPage(page)
+	case code >= http.StatusInternalServerError:
+		n = html.ErrorPage(page)
+	default:
+		n = html.ProblemPage(page, title, detail)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	_ = n.Render(w)
+}
@@ -0,0 +1,113 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	g "maragu.dev/gomponents"
+	"maragu.dev/is"
+
+	"maragu.dev/glue/html"
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/oteltest"
+)
+
+type thing struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGetResource(t *testing.T) {
+
+	newRouter := func() *gluehttp.Router {
+		mux := chi.NewMux()
+		mux.Use(gluehttp.OpenTelemetry)
+		router := gluehttp.NewRouter(gluehttp.NewRouterOpts{Mux: mux})
+		gluehttp.GetResource(router, "/things/{id}", gluehttp.Resource[thing]{
+			Handler: func(props html.PageProps) (thing, error) {
+				switch id := gluehttp.GetPathParam(props.R, "id"); id {
+				case "t_123":
+					return thing{ID: id, Name: "Thing"}, nil
+				case "t_inactive":
+					return thing{}, model.ErrorUserInactive
+				default:
+					return thing{}, model.ErrorUserNotFound
+				}
+			},
+			HTML: func(props html.PageProps, resp thing) g.Node {
+				return testPage(props, g.Text("Name: "+resp.Name))
+			},
+			Page: testPage,
+		})
+		return router
+	}
+
+	serve := func(router *gluehttp.Router, target, accept string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		router.Mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("renders HTML for browsers", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		rec := serve(newRouter(), "/things/t_123", "text/html,application/xhtml+xml,*/*;q=0.8")
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		is.Equal(t, "Name: Thing", rec.Body.String())
+		is.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+		span := lastEndedSpan(t, sr)
+		is.True(t, oteltest.HasAttribute(span.Attributes(), attribute.String("http.response.representation", "html")))
+	})
+
+	t.Run("encodes JSON for the Accept header or the .json extension", func(t *testing.T) {
+		for _, test := range []struct{ target, accept string }{
+			{"/things/t_123", "application/json"},
+			{"/things/t_123.json", ""},
+		} {
+			sr := oteltest.NewSpanRecorder(t)
+
+			rec := serve(newRouter(), test.target, test.accept)
+			is.Equal(t, http.StatusOK, rec.Code)
+			is.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var resp thing
+			is.NotError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			is.Equal(t, "t_123", resp.ID)
+
+			span := lastEndedSpan(t, sr)
+			is.True(t, oteltest.HasAttribute(span.Attributes(), attribute.String("http.response.representation", "json")))
+		}
+	})
+
+	t.Run("maps errors to the same status codes for both representations", func(t *testing.T) {
+		router := newRouter()
+
+		rec := serve(router, "/things/t_404", "")
+		is.Equal(t, http.StatusNotFound, rec.Code)
+		is.True(t, strings.Contains(rec.Body.String(), "Not found"))
+
+		rec = serve(router, "/things/t_404.json", "")
+		is.Equal(t, http.StatusNotFound, getProblem(t, rec).Status)
+
+		rec = serve(router, "/things/t_inactive", "")
+		is.Equal(t, http.StatusForbidden, rec.Code)
+		is.True(t, strings.Contains(rec.Body.String(), "Forbidden"))
+
+		rec = serve(router, "/things/t_inactive", "application/json")
+		p := getProblem(t, rec)
+		is.Equal(t, http.StatusForbidden, p.Status)
+		is.Equal(t, model.ErrorUserInactive.Error(), p.Detail)
+	})
+}
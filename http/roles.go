@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	g "maragu.dev/gomponents"
+
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+type roleGetter interface {
+	GetRoles(ctx context.Context, userID model.UserID) ([]model.Role, error)
+}
+
+type NewRolePermissionsGetterOptions struct {
+	// Roles maps the roles to the permissions they grant.
+	Roles model.RolePermissions
+
+	// Store for the user's roles, such as [maragu.dev/glue/sql.Helper].
+	Store roleGetter
+}
+
+// RolePermissionsGetter gets a user's permissions from their roles, and can be passed to [Authorize] and [SavePermissionsInContext].
+// Those look permissions up once per request and save them in the request context, so using both on a route doesn't query the store twice.
+type RolePermissionsGetter struct {
+	roles model.RolePermissions
+	store roleGetter
+}
+
+func NewRolePermissionsGetter(opts NewRolePermissionsGetterOptions) *RolePermissionsGetter {
+	return &RolePermissionsGetter{
+		roles: opts.Roles,
+		store: opts.Store,
+	}
+}
+
+// GetPermissions satisfies [permissionsGetter].
+func (rpg *RolePermissionsGetter) GetPermissions(ctx context.Context, userID model.UserID) ([]model.Permission, error) {
+	roles, err := rpg.store.GetRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return rpg.roles.Permissions(roles...), nil
+}
+
+var _ permissionsGetter = (*RolePermissionsGetter)(nil)
+
+type roleStore interface {
+	roleGetter
+	GrantRole(ctx context.Context, userID model.UserID, role model.Role) error
+	RevokeRole(ctx context.Context, userID model.UserID, role model.Role) error
+}
+
+type RoleAdminOptions struct {
+	Log *slog.Logger
+
+	// Page to render the roles page in.
+	Page html.PageFunc
+
+	// Permission required to see and change user roles.
+	Permission model.Permission
+
+	// PermissionsGetter to check the [RoleAdminOptions.Permission] with, typically a [RolePermissionsGetter].
+	PermissionsGetter permissionsGetter
+
+	// Roles that can be granted. Roles not in the mapping are rejected.
+	Roles model.RolePermissions
+
+	// Store for the user roles, such as [maragu.dev/glue/sql.Helper].
+	Store roleStore
+}
+
+// RoleAdmin registers routes for admins to grant and revoke user roles:
+//   - GET /admin/users/{id}/roles shows the roles of the user, with forms to grant and revoke them.
+//   - POST /admin/users/{id}/roles/grant grants the role in the role form value.
+//   - POST /admin/users/{id}/roles/revoke revokes the role in the role form value.
+//
+// All routes require the [RoleAdminOptions.Permission], see [Authorize].
+func RoleAdmin(r *Router, opts RoleAdminOptions) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	log := opts.Log
+
+	r.Group(func(r *Router) {
+		r.Use(AuthorizeWithOptions(AuthorizeOptions{
+			Log:               log,
+			Page:              opts.Page,
+			Permissions:       []model.Permission{opts.Permission},
+			PermissionsGetter: opts.PermissionsGetter,
+		}))
+
+		r.Get("/admin/users/{id}/roles", func(props html.PageProps) (g.Node, error) {
+			userID := model.UserID(GetPathParam(props.R, "id"))
+
+			granted, err := opts.Store.GetRoles(props.Ctx, userID)
+			if err != nil {
+				log.ErrorContext(props.Ctx, "Error getting roles", "error", err, "userID", userID)
+				return html.ErrorPage(opts.Page), err
+			}
+
+			return html.UserRolesPage(opts.Page, userID, opts.Roles.Roles(), granted), nil
+		})
+
+		r.Post("/admin/users/{id}/roles/grant", func(props html.PageProps) (g.Node, error) {
+			return changeRole(props, opts, "grant", opts.Store.GrantRole)
+		})
+
+		r.Post("/admin/users/{id}/roles/revoke", func(props html.PageProps) (g.Node, error) {
+			return changeRole(props, opts, "revoke", opts.Store.RevokeRole)
+		})
+	})
+}
+
+// changeRole of the user in the path with the role in the form, then redirects back to the roles page.
+func changeRole(props html.PageProps, opts RoleAdminOptions, action string, change func(ctx context.Context, userID model.UserID, role model.Role) error) (g.Node, error) {
+	userID := model.UserID(GetPathParam(props.R, "id"))
+	role := model.Role(props.R.FormValue("role"))
+
+	if !opts.Roles.Has(role) {
+		http.Error(props.W, "unknown role", http.StatusBadRequest)
+		return nil, nil
+	}
+
+	if err := change(props.Ctx, userID, role); err != nil {
+		opts.Log.ErrorContext(props.Ctx, "Error changing role", "error", err, "action", action, "userID", userID, "role", role)
+		return html.ErrorPage(opts.Page), err
+	}
+
+	opts.Log.InfoContext(props.Ctx, "Changed role", "action", action, "userID", userID, "role", role, "adminUserID", props.UserID)
+
+	http.Redirect(props.W, props.R, "/admin/users/"+url.PathEscape(userID.String())+"/roles", http.StatusFound)
+	return nil, nil
+}
@@ -0,0 +1,134 @@
+package http_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type mockRoleStore struct {
+	roles map[model.UserID][]model.Role
+	calls int
+}
+
+func (m *mockRoleStore) GetRoles(ctx context.Context, userID model.UserID) ([]model.Role, error) {
+	m.calls++
+	return m.roles[userID], nil
+}
+
+func (m *mockRoleStore) GrantRole(ctx context.Context, userID model.UserID, role model.Role) error {
+	if !slices.Contains(m.roles[userID], role) {
+		m.roles[userID] = append(m.roles[userID], role)
+	}
+	return nil
+}
+
+func (m *mockRoleStore) RevokeRole(ctx context.Context, userID model.UserID, role model.Role) error {
+	m.roles[userID] = slices.DeleteFunc(m.roles[userID], func(r model.Role) bool { return r == role })
+	return nil
+}
+
+var testRoles = model.RolePermissions{
+	"admin":  {"read", "write", "manage_roles"},
+	"editor": {"read", "write"},
+}
+
+func TestRolePermissionsGetter(t *testing.T) {
+	t.Run("gets permissions from the user's roles", func(t *testing.T) {
+		store := &mockRoleStore{roles: map[model.UserID][]model.Role{"u_123": {"editor", "unknown"}}}
+		rpg := gluehttp.NewRolePermissionsGetter(gluehttp.NewRolePermissionsGetterOptions{Roles: testRoles, Store: store})
+
+		permissions, err := rpg.GetPermissions(t.Context(), "u_123")
+		is.NotError(t, err)
+		is.EqualSlice(t, []model.Permission{"read", "write"}, permissions)
+	})
+
+	t.Run("gets permissions once per request", func(t *testing.T) {
+		store := &mockRoleStore{roles: map[model.UserID][]model.Role{"u_123": {"editor"}}}
+		rpg := gluehttp.NewRolePermissionsGetter(gluehttp.NewRolePermissionsGetterOptions{Roles: testRoles, Store: store})
+		log := slog.New(slog.DiscardHandler)
+
+		var permissions []model.Permission
+		h := gluehttp.SavePermissionsInContext(log, rpg)(gluehttp.Authorize(log, rpg, "write")(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				permissions = gluehttp.GetPermissionsFromContext(r.Context())
+			})))
+
+		userID := model.UserID("u_123")
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), gluehttp.ContextKey("userID"), &userID))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, 1, store.calls)
+		is.EqualSlice(t, []model.Permission{"read", "write"}, permissions)
+	})
+}
+
+func TestRoleAdmin(t *testing.T) {
+	t.Run("shows, grants, and revokes roles", func(t *testing.T) {
+		h, store := newRoleAdminHandler(t)
+
+		rec := serveForm(h, http.MethodGet, "/admin/users/u_2/roles", nil)
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.True(t, strings.Contains(rec.Body.String(), "Admin"))
+		is.True(t, strings.Contains(rec.Body.String(), "Editor"))
+
+		rec = serveForm(h, http.MethodPost, "/admin/users/u_2/roles/grant", url.Values{"role": {"editor"}})
+		is.Equal(t, http.StatusFound, rec.Code)
+		is.Equal(t, "/admin/users/u_2/roles", rec.Header().Get("Location"))
+		is.EqualSlice(t, []model.Role{"editor"}, store.roles["u_2"])
+
+		rec = serveForm(h, http.MethodPost, "/admin/users/u_2/roles/revoke", url.Values{"role": {"editor"}})
+		is.Equal(t, http.StatusFound, rec.Code)
+		is.Equal(t, 0, len(store.roles["u_2"]))
+	})
+
+	t.Run("responds with bad request for unknown roles", func(t *testing.T) {
+		h, store := newRoleAdminHandler(t)
+
+		rec := serveForm(h, http.MethodPost, "/admin/users/u_2/roles/grant", url.Values{"role": {"superuser"}})
+		is.Equal(t, http.StatusBadRequest, rec.Code)
+		is.Equal(t, 0, len(store.roles["u_2"]))
+	})
+
+	t.Run("forbids users without the permission", func(t *testing.T) {
+		h, store := newRoleAdminHandler(t)
+		store.roles["u_123"] = []model.Role{"editor"}
+
+		rec := serveForm(h, http.MethodPost, "/admin/users/u_123/roles/grant", url.Values{"role": {"admin"}})
+		is.Equal(t, http.StatusForbidden, rec.Code)
+		is.EqualSlice(t, []model.Role{"editor"}, store.roles["u_123"])
+	})
+}
+
+func newRoleAdminHandler(t *testing.T) (http.Handler, *mockRoleStore) {
+	t.Helper()
+
+	log := slog.New(slog.DiscardHandler)
+	store := &mockRoleStore{roles: map[model.UserID][]model.Role{"u_123": {"admin"}}}
+
+	router := newAuthenticatedRouter(log, true, &mockUserActiveChecker{active: true})
+
+	gluehttp.RoleAdmin(router, gluehttp.RoleAdminOptions{
+		Log:               log,
+		Page:              testPage,
+		Permission:        "manage_roles",
+		PermissionsGetter: gluehttp.NewRolePermissionsGetter(gluehttp.NewRolePermissionsGetterOptions{Roles: testRoles, Store: store}),
+		Roles:             testRoles,
+		Store:             store,
+	})
+
+	return router.Mux, store
+}
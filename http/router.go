@@ -92,11 +92,19 @@ func (r *Router) NotFound(h http.HandlerFunc) {
 
 func GetProps(w http.ResponseWriter, r *http.Request) html.PageProps {
 	return html.PageProps{
-		Ctx:         r.Context(),
-		R:           r,
-		UserID:      GetUserIDFromContext(r.Context()),
-		W:           w,
-		Permissions: GetPermissionsFromContext(r.Context()),
+		Ctx:           r.Context(),
+		R:             r,
+		UserID:        GetUserIDFromContext(r.Context()),
+		W:             w,
+		Permissions:   GetPermissionsFromContext(r.Context()),
+		Impersonating: IsImpersonating(r.Context()),
+		HTMX: html.HTMX{
+			Request:    IsHTMXRequest(r),
+			Boosted:    r.Header.Get("HX-Boosted") == "true",
+			Target:     r.Header.Get("HX-Target"),
+			Trigger:    r.Header.Get("HX-Trigger"),
+			CurrentURL: r.Header.Get("HX-Current-URL"),
+		},
 	}
 }
 
@@ -2,9 +2,12 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"maragu.dev/httph"
+
+	"maragu.dev/glue/model"
 )
 
 // setupRoutes as well as middleware.
@@ -14,6 +17,10 @@ func (s *Server) setupRoutes() {
 	r.Use(middleware.Compress(5))
 	r.Use(middleware.RealIP)
 	r.Use(OpenTelemetry)
+	if s.accessLog != nil {
+		r.Use(AccessLog(*s.accessLog))
+	}
+	r.Use(Recover(s.log, s.htmlPage))
 
 	protection := http.NewCrossOriginProtection()
 	if err := protection.AddTrustedOrigin(s.baseURL); err != nil {
@@ -23,22 +30,75 @@ func (s *Server) setupRoutes() {
 
 	r.NotFound(NotFound(s.htmlPage))
 
-	r.Group(func(r *Router) {
-		r.Use(httph.VersionedAssets)
+	// Health
+	r.Mux.Get("/health/live", s.health.LiveHandler())
+	r.Mux.Get("/health/ready", s.health.ReadyHandler())
 
-		Static(r.Mux)
-	})
+	Static(r.Mux, s.assets)
 
 	// HTML
 	r.Group(func(r *Router) {
 		r.Use(httph.NoClickjacking, httph.ContentSecurityPolicy(s.csp))
-		r.Use(s.r.SM.LoadAndSave, Authenticate(s.log, s.r.SM, s.userActiveChecker))
+
+		uac := s.userActiveChecker
+		var sd sessionDestroyer = s.r.SM
+		if s.userSessionStore != nil {
+			uac = sessionRevokingUserActiveChecker{uac: uac, sr: s.userSessionStore}
+			sd = sessionRevokingDestroyer{sd: sd, sr: s.userSessionStore}
+		}
+
+		r.Use(s.r.SM.LoadAndSave, Authenticate(s.log, s.r.SM, uac))
+
+		if s.accessTokenAuthenticator != nil {
+			r.Use(AuthenticateBearer(s.log, s.accessTokenAuthenticator, uac))
+		}
+
+		if s.userSessionStore != nil {
+			r.Use(TrackSessions(s.log, s.r.SM, s.userSessionStore))
+		}
+
+		var impersonationOpts ImpersonationOptions
+		if s.impersonationStore != nil {
+			impersonationOpts = ImpersonationOptions{
+				Log:               s.log,
+				Permission:        s.impersonationPermission,
+				PermissionsGetter: s.permissionsGetter,
+				Session:           s.r.SM,
+				Store:             s.impersonationStore,
+				Users:             s.userActiveChecker,
+			}
+			r.Use(Impersonate(impersonationOpts))
+		}
 
 		if s.permissionsGetter != nil {
 			r.Use(SavePermissionsInContext(s.log, s.permissionsGetter))
 		}
 
-		Logout(r, s.log, s.r.SM, s.htmlPage)
+		if s.idempotencyStore != nil {
+			r.Use(Idempotency(IdempotencyOptions{Log: s.log, Page: s.htmlPage, Store: s.idempotencyStore}))
+		}
+
+		r.Group(func(r *Router) {
+			if s.rateLimiter != nil {
+				r.Use(RateLimit(RateLimitOptions{
+					Limit:   model.RateLimit{Burst: 10, Every: 6 * time.Second},
+					Limiter: s.rateLimiter,
+					Log:     s.log,
+					Name:    "server",
+					Page:    s.htmlPage,
+				}))
+			}
+
+			Logout(r, s.log, sd, s.htmlPage)
+		})
+
+		if s.impersonationStore != nil {
+			Impersonation(r, impersonationOpts)
+		}
+
+		if s.userSessionStore != nil {
+			Sessions(r, SessionsOptions{Log: s.log, Page: s.htmlPage, Store: s.userSessionStore})
+		}
 
 		r.Group(func(r *Router) {
 			if s.httpRouterInjector != nil {
@@ -17,36 +17,78 @@ import (
 
 	"maragu.dev/httph"
 
+	"maragu.dev/glue/health"
 	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
 )
 
 type Server struct {
-	baseURL            string
-	csp                func(opts *httph.ContentSecurityPolicyOptions)
-	htmlPage           html.PageFunc
-	httpRouterInjector func(*Router)
-	log                *slog.Logger
-	permissionsGetter  permissionsGetter
-	r                  *Router
-	server             *http.Server
-	tracer             trace.Tracer
-	userActiveChecker  userActiveChecker
+	accessLog                *AccessLogOptions
+	accessTokenAuthenticator accessTokenAuthenticator
+	adminServer              *http.Server
+	assets                   *Assets
+	baseURL                  string
+	csp                      func(opts *httph.ContentSecurityPolicyOptions)
+	health                   *health.Registry
+	htmlPage                 html.PageFunc
+	httpRouterInjector       func(*Router)
+	idempotencyStore         idempotencyStore
+	impersonationPermission  model.Permission
+	impersonationStore       impersonationStore
+	log                      *slog.Logger
+	permissionsGetter        permissionsGetter
+	rateLimiter              rateLimiter
+	r                        *Router
+	server                   *http.Server
+	shutdownDelay            time.Duration
+	tracer                   trace.Tracer
+	userActiveChecker        userActiveChecker
+	userSessionStore         sessionStore
 }
 
 type NewServerOptions struct {
-	Address            string
-	BaseURL            string
-	CSP                func(opts *httph.ContentSecurityPolicyOptions)
-	HTMLPage           html.PageFunc
-	HTTPRouterInjector func(*Router)
-	Log                *slog.Logger
-	PermissionsGetter  permissionsGetter
-	SecureCookie       bool
-	SessionStore       scs.Store
-	UserActiveChecker  userActiveChecker
-	WriteTimeout       time.Duration
+	AccessLog                *AccessLogOptions
+	AccessTokenAuthenticator accessTokenAuthenticator
+	Address                  string
+	AdminAddress             string
+	Assets                   *Assets
+	BaseURL                  string
+	CSP                      func(opts *httph.ContentSecurityPolicyOptions)
+	Health                   *health.Registry
+	HTMLPage                 html.PageFunc
+	HTTPRouterInjector       func(*Router)
+	IdempotencyStore         idempotencyStore
+	ImpersonationPermission  model.Permission
+	ImpersonationStore       impersonationStore
+	Log                      *slog.Logger
+	LogLevel                 *slog.LevelVar
+	PermissionsGetter        permissionsGetter
+	RateLimiter              rateLimiter
+	SecureCookie             bool
+	SessionStore             scs.Store
+	ShutdownDelay            time.Duration
+	UserActiveChecker        userActiveChecker
+	UserSessionStore         sessionStore
+	WriteTimeout             time.Duration
 }
 
+// NewServer with the given options.
+// Liveness and readiness checks from the [health.Registry] are served at /health/live and /health/ready.
+// When stopping, readiness fails first, and the server waits for ShutdownDelay before stopping,
+// so load balancers have time to drain traffic. Draining and stopping together time out after one minute.
+// Static files are served from Assets with [Static], by default from the "public" directory, see [NewAssets].
+// If AccessLog is set, each request is logged with [AccessLog], by default with the server logger.
+// If AccessTokenAuthenticator is set, requests can also authenticate with personal access tokens, see [AuthenticateBearer].
+// If UserSessionStore is set, logged-in sessions are recorded with [TrackSessions], users can see and revoke them with [Sessions],
+// and all sessions of users found to be inactive are revoked.
+// If IdempotencyStore is set, repeated POST and PATCH requests with the same idempotency key replay the first response,
+// see [Idempotency].
+// If ImpersonationStore is set, users with ImpersonationPermission (checked with PermissionsGetter) can impersonate other users,
+// see [Impersonate] and [Impersonation].
+// If RateLimiter is set, the routes registered by the server, such as /logout, are rate limited per IP with [RateLimit].
+// Use the same RateLimiter with [RateLimit] for app routes such as login and signup.
+// If AdminAddress is set, a separate listener serves [NewAdminHandler] on it, with LogLevel changeable at runtime.
+// Bind it to localhost or a private port, as it is not protected by any authentication.
 func NewServer(opts NewServerOptions) *Server {
 	if opts.Log == nil {
 		opts.Log = slog.New(slog.DiscardHandler)
@@ -60,6 +102,10 @@ func NewServer(opts NewServerOptions) *Server {
 		opts.WriteTimeout = 10 * time.Second
 	}
 
+	if opts.Health == nil {
+		opts.Health = health.NewRegistry(health.NewRegistryOptions{})
+	}
+
 	sm := scs.New()
 	if opts.SessionStore != nil {
 		sm.Store = opts.SessionStore
@@ -72,14 +118,51 @@ func NewServer(opts NewServerOptions) *Server {
 
 	mux := &TracingMux{mux: chi.NewRouter(), tracer: tracer}
 
+	var adminServer *http.Server
+	if opts.AdminAddress != "" {
+		adminServer = &http.Server{
+			Addr:     opts.AdminAddress,
+			ErrorLog: slog.NewLogLogger(opts.Log.Handler(), slog.LevelError),
+			Handler: NewAdminHandler(NewAdminHandlerOptions{
+				LogLevel: opts.LogLevel,
+				Routes:   mux,
+			}),
+			IdleTimeout: time.Minute,
+			ReadTimeout: 10 * time.Second,
+			// No write timeout, because CPU profiles and traces stream for as long as requested
+		}
+	}
+
+	if opts.Assets == nil {
+		opts.Assets = NewAssets(NewAssetsOptions{})
+	}
+
+	var accessLog *AccessLogOptions
+	if opts.AccessLog != nil {
+		accessLogOpts := *opts.AccessLog
+		if accessLogOpts.Log == nil {
+			accessLogOpts.Log = opts.Log
+		}
+		accessLog = &accessLogOpts
+	}
+
 	return &Server{
-		baseURL:            opts.BaseURL,
-		csp:                opts.CSP,
-		htmlPage:           opts.HTMLPage,
-		httpRouterInjector: opts.HTTPRouterInjector,
-		log:                opts.Log,
-		permissionsGetter:  opts.PermissionsGetter,
-		r:                  &Router{Mux: mux, SM: sm},
+		accessLog:                accessLog,
+		accessTokenAuthenticator: opts.AccessTokenAuthenticator,
+		adminServer:              adminServer,
+		assets:                   opts.Assets,
+		baseURL:                  opts.BaseURL,
+		csp:                      opts.CSP,
+		health:                   opts.Health,
+		htmlPage:                 opts.HTMLPage,
+		httpRouterInjector:       opts.HTTPRouterInjector,
+		idempotencyStore:         opts.IdempotencyStore,
+		impersonationPermission:  opts.ImpersonationPermission,
+		impersonationStore:       opts.ImpersonationStore,
+		log:                      opts.Log,
+		permissionsGetter:        opts.PermissionsGetter,
+		rateLimiter:              opts.RateLimiter,
+		r:                        &Router{Mux: mux, SM: sm},
 		server: &http.Server{
 			Addr:         opts.Address,
 			ErrorLog:     slog.NewLogLogger(opts.Log.Handler(), slog.LevelError),
@@ -88,8 +171,10 @@ func NewServer(opts NewServerOptions) *Server {
 			ReadTimeout:  10 * time.Second,
 			WriteTimeout: opts.WriteTimeout,
 		},
+		shutdownDelay:     opts.ShutdownDelay,
 		tracer:            tracer,
 		userActiveChecker: opts.UserActiveChecker,
+		userSessionStore:  opts.UserSessionStore,
 	}
 }
 
@@ -108,6 +193,17 @@ func (s *Server) Start(ctx context.Context) error {
 		return nil
 	})
 
+	if s.adminServer != nil {
+		s.log.InfoContext(ctx, "Starting admin server", "address", s.adminServer.Addr)
+
+		eg.Go(func() error {
+			if err := s.adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+	}
+
 	eg.Go(func() error {
 		<-ctx.Done()
 		return s.stop(ctx)
@@ -116,14 +212,36 @@ func (s *Server) Start(ctx context.Context) error {
 	return eg.Wait()
 }
 
-// stop the Server gracefully, waiting for existing HTTP connections to finish.
+// stop the Server gracefully, draining it first, and waiting for existing HTTP connections to finish.
+// Draining counts towards the one minute stop timeout.
 func (s *Server) stop(ctx context.Context) error {
-	s.log.InfoContext(ctx, "Stopping server")
-
 	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
 	defer cancel()
 
-	if err := s.server.Shutdown(ctx); err != nil {
+	// Fail readiness first, and give load balancers a chance to notice before we stop accepting connections
+	s.health.Shutdown()
+	if s.shutdownDelay > 0 {
+		s.log.InfoContext(ctx, "Draining server before stopping", "delay", s.shutdownDelay)
+
+		timer := time.NewTimer(s.shutdownDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	s.log.InfoContext(ctx, "Stopping server")
+
+	err := s.server.Shutdown(ctx)
+
+	// The admin server is stopped last, so it's available for debugging while draining.
+	// It's stopped even if stopping the server failed, so its ListenAndServe returns.
+	if s.adminServer != nil {
+		err = errors.Join(err, s.adminServer.Shutdown(ctx))
+	}
+
+	if err != nil {
 		return err
 	}
 
@@ -0,0 +1,273 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/mileusna/useragent"
+	g "maragu.dev/gomponents"
+
+	"maragu.dev/glue/html"
+	"maragu.dev/glue/model"
+)
+
+const contextSessionIDKey = ContextKey("sessionID")
+
+// sessionIDKey in the session, for the ID of the [model.Session] tracked by [TrackSessions].
+const sessionIDKey = "sessionID"
+
+type sessionTracker interface {
+	CreateSession(ctx context.Context, userID model.UserID, ip, browser, os string) (model.Session, error)
+	// TouchSession returns [model.ErrorSessionNotFound] if the session has been revoked.
+	TouchSession(ctx context.Context, id model.SessionID) (model.Session, error)
+}
+
+type sessionRevoker interface {
+	// RevokeSession returns [model.ErrorSessionNotFound] if the user has no session with the ID.
+	RevokeSession(ctx context.Context, userID model.UserID, id model.SessionID) error
+	RevokeSessions(ctx context.Context, userID model.UserID) error
+}
+
+type sessionStore interface {
+	sessionTracker
+	sessionRevoker
+	GetSessions(ctx context.Context, userID model.UserID) ([]model.Session, error)
+}
+
+type sessionGetPutDestroyer interface {
+	sessionGetterDestroyer
+	Put(ctx context.Context, key string, val any)
+}
+
+// TrackSessions is [Middleware] to record metadata about logged-in sessions, so users can see and revoke them, see [Sessions].
+// It must come after [Authenticate]. New sessions are recorded with the IP address and parsed user agent of the request.
+// If the session has been revoked, the middleware destroys it and calls the next handler without a user ID in the context.
+// Requests authenticated with an access token are not tracked.
+func TrackSessions(log *slog.Logger, sm sessionGetPutDestroyer, st sessionTracker) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			userID := GetUserIDFromContext(ctx)
+			if userID == nil || GetAccessTokenFromContext(ctx) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if id := model.SessionID(sm.GetString(ctx, sessionIDKey)); id != "" {
+				s, err := st.TouchSession(ctx, id)
+				switch {
+				case err == nil && s.UserID == *userID:
+					ctx = context.WithValue(ctx, contextSessionIDKey, s.ID)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+
+				case errors.Is(err, model.ErrorSessionNotFound):
+					if err := sm.Destroy(ctx); err != nil {
+						log.InfoContext(ctx, "Error destroying revoked session", "error", err, "userID", userID)
+						http.Error(w, "error destroying revoked session", http.StatusInternalServerError)
+						return
+					}
+
+					// The revoked session is destroyed, and the request continues without a user
+					ctx = withUserID(ctx, nil)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+
+				case err != nil:
+					log.InfoContext(ctx, "Error getting session", "error", err, "userID", userID)
+					http.Error(w, "error getting session", http.StatusInternalServerError)
+					return
+				}
+
+				// The session belongs to another user, so the user logged in without logging out first. Track a new session.
+			}
+
+			ua := useragent.Parse(r.UserAgent())
+			s, err := st.CreateSession(ctx, *userID, getIP(r), strings.TrimSpace(ua.Name+" "+ua.Version), strings.TrimSpace(ua.OS+" "+ua.OSVersion))
+			if err != nil {
+				log.InfoContext(ctx, "Error creating session", "error", err, "userID", userID)
+				http.Error(w, "error creating session", http.StatusInternalServerError)
+				return
+			}
+			sm.Put(ctx, sessionIDKey, s.ID.String())
+
+			ctx = context.WithValue(ctx, contextSessionIDKey, s.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// getIP of the request, without the port. Use [middleware.RealIP] to get the client IP behind a proxy.
+func getIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// GetSessionIDFromContext, which is empty if the session is not tracked by [TrackSessions].
+func GetSessionIDFromContext(ctx context.Context) model.SessionID {
+	id, _ := ctx.Value(contextSessionIDKey).(model.SessionID)
+	return id
+}
+
+// sessionRevokingUserActiveChecker revokes all sessions of inactive users when checking whether they're active,
+// so they're logged out everywhere at once instead of only in the session making the request.
+type sessionRevokingUserActiveChecker struct {
+	uac userActiveChecker
+	sr  sessionRevoker
+}
+
+// IsUserActive satisfies [userActiveChecker].
+func (c sessionRevokingUserActiveChecker) IsUserActive(ctx context.Context, id model.UserID) (bool, error) {
+	active, err := c.uac.IsUserActive(ctx, id)
+	if err != nil || active {
+		return active, err
+	}
+
+	if err := c.sr.RevokeSessions(ctx, id); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// sessionRevokingDestroyer revokes the tracked session when destroying it, so it doesn't show up as active after logging out.
+type sessionRevokingDestroyer struct {
+	sd sessionDestroyer
+	sr sessionRevoker
+}
+
+// Destroy satisfies [sessionDestroyer].
+func (d sessionRevokingDestroyer) Destroy(ctx context.Context) error {
+	if userID, id := GetRealUserIDFromContext(ctx), GetSessionIDFromContext(ctx); userID != nil && id != "" {
+		if err := d.sr.RevokeSession(ctx, *userID, id); err != nil && !errors.Is(err, model.ErrorSessionNotFound) {
+			return err
+		}
+	}
+	return d.sd.Destroy(ctx)
+}
+
+type SessionsOptions struct {
+	Log *slog.Logger
+
+	// LoginURL to redirect anonymous users to, with a redirect query parameter to get back afterwards. Defaults to /login.
+	LoginURL string
+
+	// Page to render the sessions page in.
+	Page html.PageFunc
+
+	// Session to destroy when revoking the current session. Defaults to the [Router] session manager.
+	Session sessionDestroyer
+
+	// Store for the sessions recorded by [TrackSessions], such as [maragu.dev/glue/sql.Helper].
+	Store sessionStore
+}
+
+// Sessions registers routes for logged-in users to see and revoke their sessions:
+//   - GET /profile/sessions lists the user's active sessions, with the current one marked.
+//   - POST /profile/sessions/{id}/revoke revokes a session, which logs it out on its next request.
+//   - POST /profile/sessions/revoke revokes all sessions, logging the user out everywhere.
+//
+// Revoking the current session logs the user out and redirects to the front page.
+func Sessions(r *Router, opts SessionsOptions) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	if opts.LoginURL == "" {
+		opts.LoginURL = "/login"
+	}
+
+	if opts.Session == nil {
+		opts.Session = r.SM
+	}
+
+	log := opts.Log
+
+	r.Get("/profile/sessions", func(props html.PageProps) (g.Node, error) {
+		userID, ok, err := getSessionUserIDOrRedirect(props, opts.LoginURL)
+		if !ok {
+			return nil, err
+		}
+
+		sessions, err := opts.Store.GetSessions(props.Ctx, userID)
+		if err != nil {
+			log.ErrorContext(props.Ctx, "Error getting sessions", "error", err, "userID", userID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		return html.SessionsPage(opts.Page, sessions, GetSessionIDFromContext(props.Ctx)), nil
+	})
+
+	r.Post("/profile/sessions/{id}/revoke", func(props html.PageProps) (g.Node, error) {
+		userID, ok, err := getSessionUserIDOrRedirect(props, opts.LoginURL)
+		if !ok {
+			return nil, err
+		}
+
+		id := model.SessionID(GetPathParam(props.R, "id"))
+		if err := opts.Store.RevokeSession(props.Ctx, userID, id); err != nil {
+			if errors.Is(err, model.ErrorSessionNotFound) {
+				return html.NotFoundPage(opts.Page), Error{Code: http.StatusNotFound}
+			}
+			log.ErrorContext(props.Ctx, "Error revoking session", "error", err, "userID", userID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		log.InfoContext(props.Ctx, "Revoked session", "userID", userID, "sessionID", id)
+
+		if id != GetSessionIDFromContext(props.Ctx) {
+			http.Redirect(props.W, props.R, "/profile/sessions", http.StatusFound)
+			return nil, nil
+		}
+
+		if err := opts.Session.Destroy(props.Ctx); err != nil {
+			log.ErrorContext(props.Ctx, "Error destroying session", "error", err, "userID", userID)
+			return html.ErrorPage(opts.Page), err
+		}
+		http.Redirect(props.W, props.R, "/", http.StatusFound)
+		return nil, nil
+	})
+
+	r.Post("/profile/sessions/revoke", func(props html.PageProps) (g.Node, error) {
+		userID, ok, err := getSessionUserIDOrRedirect(props, opts.LoginURL)
+		if !ok {
+			return nil, err
+		}
+
+		if err := opts.Store.RevokeSessions(props.Ctx, userID); err != nil {
+			log.ErrorContext(props.Ctx, "Error revoking sessions", "error", err, "userID", userID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		if err := opts.Session.Destroy(props.Ctx); err != nil {
+			log.ErrorContext(props.Ctx, "Error destroying session", "error", err, "userID", userID)
+			return html.ErrorPage(opts.Page), err
+		}
+
+		log.InfoContext(props.Ctx, "Revoked all sessions", "userID", userID)
+
+		http.Redirect(props.W, props.R, "/", http.StatusFound)
+		return nil, nil
+	})
+}
+
+// getSessionUserIDOrRedirect returns the ID of the user logged in with a session.
+// If there's no user, it redirects to the login URL and returns false. Requests authenticated with an access token get an error.
+func getSessionUserIDOrRedirect(props html.PageProps, loginURL string) (model.UserID, bool, error) {
+	if props.UserID == nil {
+		http.Redirect(props.W, props.R, getLoginURL(loginURL, props.R.URL.Path), http.StatusFound)
+		return "", false, nil
+	}
+
+	userID, err := getSessionUserID(props.Ctx)
+	if err != nil {
+		return "", false, err
+	}
+	return userID, true, nil
+}
@@ -0,0 +1,186 @@
+package http_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+	"maragu.dev/glue/model"
+)
+
+type mockSessionStore struct {
+	sessions []model.Session
+}
+
+func (m *mockSessionStore) CreateSession(ctx context.Context, userID model.UserID, ip, browser, os string) (model.Session, error) {
+	s := model.Session{ID: model.SessionID("s_" + string(rune('a'+len(m.sessions)))), UserID: userID, IP: ip, Browser: browser, OS: os}
+	m.sessions = append(m.sessions, s)
+	return s, nil
+}
+
+func (m *mockSessionStore) TouchSession(ctx context.Context, id model.SessionID) (model.Session, error) {
+	for _, s := range m.sessions {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return model.Session{}, model.ErrorSessionNotFound
+}
+
+func (m *mockSessionStore) GetSessions(ctx context.Context, userID model.UserID) ([]model.Session, error) {
+	var sessions []model.Session
+	for _, s := range m.sessions {
+		if s.UserID == userID {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionStore) RevokeSession(ctx context.Context, userID model.UserID, id model.SessionID) error {
+	for i, s := range m.sessions {
+		if s.ID == id && s.UserID == userID {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return model.ErrorSessionNotFound
+}
+
+func (m *mockSessionStore) RevokeSessions(ctx context.Context, userID model.UserID) error {
+	var sessions []model.Session
+	for _, s := range m.sessions {
+		if s.UserID != userID {
+			sessions = append(sessions, s)
+		}
+	}
+	m.sessions = sessions
+	return nil
+}
+
+func TestSessions(t *testing.T) {
+	t.Run("records a session with the parsed user agent and lists it", func(t *testing.T) {
+		client, store := newSessionsServer(t, gluehttp.SessionsOptions{})
+
+		client.get(t, "/login-as")
+		client.get(t, "/whoami")
+		is.Equal(t, 1, len(store.sessions))
+		is.Equal(t, model.UserID("u_123"), store.sessions[0].UserID)
+		is.Equal(t, "127.0.0.1", store.sessions[0].IP)
+		is.Equal(t, "Firefox 130.0", store.sessions[0].Browser)
+		is.Equal(t, "macOS 10.15", store.sessions[0].OS)
+
+		res := client.get(t, "/profile/sessions")
+		is.Equal(t, http.StatusOK, res.StatusCode)
+		body := readBody(t, res)
+		is.True(t, strings.Contains(body, "Firefox 130.0 on macOS 10.15"))
+		is.True(t, strings.Contains(body, "(this device)"))
+		is.Equal(t, 1, len(store.sessions))
+	})
+
+	t.Run("logs out a revoked session on its next request", func(t *testing.T) {
+		client, store := newSessionsServer(t, gluehttp.SessionsOptions{})
+
+		client.get(t, "/login-as")
+		res := client.get(t, "/whoami")
+		is.Equal(t, "u_123", readBody(t, res))
+
+		is.NotError(t, store.RevokeSessions(t.Context(), "u_123"))
+
+		res = client.get(t, "/whoami")
+		is.Equal(t, "", readBody(t, res))
+	})
+
+	t.Run("revokes another session and stays logged in", func(t *testing.T) {
+		client, store := newSessionsServer(t, gluehttp.SessionsOptions{})
+		other, _ := store.CreateSession(t.Context(), "u_123", "127.0.0.2", "", "")
+
+		client.get(t, "/login-as")
+
+		res := client.postForm(t, "/profile/sessions/"+other.ID.String()+"/revoke", nil)
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.Equal(t, "/profile/sessions", res.Header.Get("Location"))
+		is.Equal(t, 1, len(store.sessions))
+
+		res = client.get(t, "/whoami")
+		is.Equal(t, "u_123", readBody(t, res))
+	})
+
+	t.Run("logs out everywhere", func(t *testing.T) {
+		client, store := newSessionsServer(t, gluehttp.SessionsOptions{})
+		_, _ = store.CreateSession(t.Context(), "u_123", "127.0.0.2", "", "")
+		_, _ = store.CreateSession(t.Context(), "u_456", "127.0.0.3", "", "")
+
+		client.get(t, "/login-as")
+
+		res := client.postForm(t, "/profile/sessions/revoke", url.Values{})
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.Equal(t, "/", res.Header.Get("Location"))
+		is.Equal(t, 1, len(store.sessions))
+		is.Equal(t, model.UserID("u_456"), store.sessions[0].UserID)
+
+		res = client.get(t, "/whoami")
+		is.Equal(t, "", readBody(t, res))
+	})
+
+	t.Run("redirects to login if not logged in", func(t *testing.T) {
+		client, _ := newSessionsServer(t, gluehttp.SessionsOptions{})
+
+		res := client.get(t, "/profile/sessions")
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.Equal(t, "/login?redirect=%2Fprofile%2Fsessions", res.Header.Get("Location"))
+	})
+
+	t.Run("redirects to the configured login URL if not logged in", func(t *testing.T) {
+		client, _ := newSessionsServer(t, gluehttp.SessionsOptions{LoginURL: "/signin"})
+
+		res := client.get(t, "/profile/sessions")
+		is.Equal(t, http.StatusFound, res.StatusCode)
+		is.Equal(t, "/signin?redirect=%2Fprofile%2Fsessions", res.Header.Get("Location"))
+	})
+}
+
+func newSessionsServer(t *testing.T, opts gluehttp.SessionsOptions) (*testClient, *mockSessionStore) {
+	t.Helper()
+
+	log := slog.New(slog.DiscardHandler)
+	sm := scs.New()
+	store := &mockSessionStore{}
+
+	router := gluehttp.NewRouter(gluehttp.NewRouterOpts{SM: sm})
+	router.Use(gluehttp.Authenticate(log, sm, &mockUserActiveChecker{active: true}))
+	router.Use(gluehttp.TrackSessions(log, sm, store))
+
+	opts.Log = log
+	opts.Page = testPage
+	opts.Store = store
+	gluehttp.Sessions(router, opts)
+
+	router.Mux.Get("/login-as", func(w http.ResponseWriter, r *http.Request) {
+		sm.Put(r.Context(), gluehttp.SessionUserIDKey, "u_123")
+	})
+
+	router.Mux.Get("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if userID := gluehttp.GetUserIDFromContext(r.Context()); userID != nil {
+			_, _ = w.Write([]byte(userID.String()))
+		}
+	})
+
+	client := newTestClient(t, sm.LoadAndSave(router.Mux))
+	client.c.Transport = userAgentTransport{}
+	return client, store
+}
+
+type userAgentTransport struct{}
+
+func (userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:130.0) Gecko/20100101 Firefox/130.0")
+	return http.DefaultTransport.RoundTrip(req)
+}
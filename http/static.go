@@ -1,14 +1,235 @@
 package http
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
 	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
 )
 
-func Static(mux chi.Router) {
-	// TODO add default static files like robots.txt
-	staticHandler := http.FileServer(http.Dir("public"))
-	mux.Get(`/{:[^.]+\.[^.]+}`, staticHandler.ServeHTTP)
-	mux.Get(`/{:images|scripts|styles}/*`, staticHandler.ServeHTTP)
+// fingerprintedPathMatcher matches paths like /styles/app.0123456789abcdef.css,
+// and also other versioned paths like /styles/app.v2.css.
+// The fingerprint is the last segment before the extension, so names and directories can contain dots,
+// like /scripts/htmx.min.0123456789abcdef.js.
+var fingerprintedPathMatcher = regexp.MustCompile(`^(?P<name>.+)\.(?P<fingerprint>[a-z0-9]+)(?P<extension>\.[^./]+)$`)
+
+type Assets struct {
+	created time.Time
+	files   map[string]assetFile
+	fs      fs.FS
+	lock    sync.Mutex
+	name    string
+}
+
+type assetFile struct {
+	hash    string
+	modTime time.Time
+	size    int64
+}
+
+type NewAssetsOptions struct {
+	// FS to serve files from, such as an [embed.FS]. Defaults to the "public" directory in the working directory.
+	FS fs.FS
+
+	// Name of the app, used in the default manifest.json.
+	Name string
+}
+
+// NewAssets to serve with [Static] and to get fingerprinted paths with [Assets.Path].
+// Files are hashed on first use, and hashed again if their size or modification time changes,
+// so files on disk can change while the app is running.
+func NewAssets(opts NewAssetsOptions) *Assets {
+	if opts.FS == nil {
+		opts.FS = os.DirFS("public")
+	}
+
+	return &Assets{
+		created: time.Now(),
+		files:   map[string]assetFile{},
+		fs:      opts.FS,
+		name:    opts.Name,
+	}
+}
+
+// Path of the asset with the given name, fingerprinted with a hash of its content, such as /styles/app.0123456789abcdef.css.
+// Fingerprinted paths are served by [Static] with immutable caching.
+// If the asset doesn't exist or doesn't have an extension, the name is returned unchanged.
+func (a *Assets) Path(name string) string {
+	ext := path.Ext(name)
+	if ext == "" {
+		return name
+	}
+
+	f, err := a.file(name)
+	if err != nil {
+		return name
+	}
+	return strings.TrimSuffix(name, ext) + "." + f.hash + ext
+}
+
+// Href attribute with the fingerprinted [Assets.Path] of the asset with the given name.
+func (a *Assets) Href(name string) g.Node {
+	return h.Href(a.Path(name))
+}
+
+// Src attribute with the fingerprinted [Assets.Path] of the asset with the given name.
+func (a *Assets) Src(name string) g.Node {
+	return h.Src(a.Path(name))
+}
+
+// file info for the asset with the given name, hashing it if it's new or changed.
+func (a *Assets) file(name string) (assetFile, error) {
+	name = strings.TrimPrefix(name, "/")
+
+	info, err := fs.Stat(a.fs, name)
+	if err != nil {
+		return assetFile{}, err
+	}
+	if info.IsDir() {
+		return assetFile{}, fs.ErrNotExist
+	}
+
+	modTime := info.ModTime()
+	if modTime.IsZero() {
+		// Embedded files don't have a modification time, but can't change while the app is running
+		modTime = a.created
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if f, ok := a.files[name]; ok && f.modTime.Equal(modTime) && f.size == info.Size() {
+		return f, nil
+	}
+
+	content, err := fs.ReadFile(a.fs, name)
+	if err != nil {
+		return assetFile{}, err
+	}
+	sum := sha256.Sum256(content)
+
+	f := assetFile{
+		hash:    hex.EncodeToString(sum[:8]),
+		modTime: modTime,
+		size:    info.Size(),
+	}
+	a.files[name] = f
+	return f, nil
+}
+
+// ServeHTTP serves the asset at the request path, or at the path without a fingerprint.
+// Fingerprinted paths matching the current content get an immutable Cache-Control header,
+// other assets must be revalidated with ETag or Last-Modified.
+// If the FS has no robots.txt or manifest.json, defaults are served.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path
+	cacheControl := "public, no-cache"
+
+	f, err := a.file(name)
+	if err != nil {
+		if matches := fingerprintedPathMatcher.FindStringSubmatch(name); matches != nil {
+			name = matches[1] + matches[3]
+			f, err = a.file(name)
+			if err == nil && matches[2] == f.hash {
+				cacheControl = "public, max-age=31536000, immutable"
+			}
+		}
+	}
+
+	if err != nil {
+		switch name {
+		case "/robots.txt":
+			a.serveDefault(w, r, name, []byte("User-agent: *\nAllow: /\n"))
+		case "/manifest.json":
+			a.serveDefault(w, r, name, a.defaultManifest())
+		default:
+			http.NotFound(w, r)
+		}
+		return
+	}
+
+	file, err := a.fs.Open(strings.TrimPrefix(name, "/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	rs, ok := file.(io.ReadSeeker)
+	if !ok {
+		content, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, "error reading file", http.StatusInternalServerError)
+			return
+		}
+		rs = bytes.NewReader(content)
+	}
+
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", `"`+f.hash+`"`)
+	http.ServeContent(w, r, name, f.modTime, rs)
+}
+
+func (a *Assets) serveDefault(w http.ResponseWriter, r *http.Request, name string, content []byte) {
+	w.Header().Set("Cache-Control", "public, no-cache")
+	http.ServeContent(w, r, name, a.created, bytes.NewReader(content))
+}
+
+type manifest struct {
+	Name      string         `json:"name,omitempty"`
+	ShortName string         `json:"short_name,omitempty"`
+	StartURL  string         `json:"start_url"`
+	Display   string         `json:"display"`
+	Icons     []manifestIcon `json:"icons,omitempty"`
+}
+
+type manifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose"`
+}
+
+// defaultManifest for the app, with the web app manifest icons that exist.
+func (a *Assets) defaultManifest() []byte {
+	m := manifest{
+		Name:      a.name,
+		ShortName: a.name,
+		StartURL:  "/",
+		Display:   "standalone",
+	}
+
+	for _, size := range []string{"192x192", "512x512"} {
+		name := "/web-app-manifest-" + size + ".png"
+		if _, err := a.file(name); err == nil {
+			m.Icons = append(m.Icons, manifestIcon{Src: a.Path(name), Sizes: size, Type: "image/png", Purpose: "maskable"})
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Static serves the [Assets] in the root directory and in the images, scripts, and styles directories.
+func Static(mux chi.Router, assets *Assets) {
+	mux.Get(`/{:[^/]+\.[^/]+}`, assets.ServeHTTP)
+	mux.Get(`/{:images|scripts|styles}/*`, assets.ServeHTTP)
 }
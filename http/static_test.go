@@ -0,0 +1,115 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"maragu.dev/is"
+
+	gluehttp "maragu.dev/glue/http"
+)
+
+func TestStatic(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newAssets := func() (*gluehttp.Assets, *chi.Mux) {
+		assets := gluehttp.NewAssets(gluehttp.NewAssetsOptions{
+			FS: fstest.MapFS{
+				"styles/app.css":               {Data: []byte("body {}"), ModTime: modTime},
+				"favicon.ico":                  {Data: []byte("icon"), ModTime: modTime},
+				"scripts/htmx.min.js":          {Data: []byte("htmx"), ModTime: modTime},
+				"styles/v1.2/app.css":          {Data: []byte("v1.2"), ModTime: modTime},
+				"web-app-manifest-192x192.png": {Data: []byte("png")},
+			},
+			Name: "Glue",
+		})
+		mux := chi.NewMux()
+		gluehttp.Static(mux, assets)
+		return assets, mux
+	}
+
+	serve := func(mux *chi.Mux, path string, header http.Header) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("serves fingerprinted paths with immutable caching", func(t *testing.T) {
+		assets, mux := newAssets()
+
+		path := assets.Path("/styles/app.css")
+		is.True(t, path != "/styles/app.css")
+		is.True(t, strings.HasPrefix(path, "/styles/app."))
+		is.True(t, strings.HasSuffix(path, ".css"))
+
+		rec := serve(mux, path, nil)
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, "body {}", rec.Body.String())
+		is.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+		is.True(t, rec.Header().Get("ETag") != "")
+	})
+
+	t.Run("serves fingerprinted paths with dots in the name or directory", func(t *testing.T) {
+		assets, mux := newAssets()
+
+		for name, body := range map[string]string{"/scripts/htmx.min.js": "htmx", "/styles/v1.2/app.css": "v1.2"} {
+			path := assets.Path(name)
+			is.True(t, path != name)
+
+			rec := serve(mux, path, nil)
+			is.Equal(t, http.StatusOK, rec.Code)
+			is.Equal(t, body, rec.Body.String())
+			is.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+		}
+	})
+
+	t.Run("serves unfingerprinted and stale paths with revalidation", func(t *testing.T) {
+		_, mux := newAssets()
+
+		for _, path := range []string{"/styles/app.css", "/styles/app.v2.css", "/favicon.ico"} {
+			rec := serve(mux, path, nil)
+			is.Equal(t, http.StatusOK, rec.Code)
+			is.Equal(t, "public, no-cache", rec.Header().Get("Cache-Control"))
+			is.Equal(t, modTime.Format(http.TimeFormat), rec.Header().Get("Last-Modified"))
+		}
+	})
+
+	t.Run("responds with not modified for matching ETags", func(t *testing.T) {
+		_, mux := newAssets()
+
+		rec := serve(mux, "/styles/app.css", nil)
+		rec = serve(mux, "/styles/app.css", http.Header{"If-None-Match": {rec.Header().Get("ETag")}})
+		is.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("returns the name unchanged for missing assets", func(t *testing.T) {
+		assets, mux := newAssets()
+
+		is.Equal(t, "/styles/missing.css", assets.Path("/styles/missing.css"))
+		is.Equal(t, http.StatusNotFound, serve(mux, "/styles/missing.css", nil).Code)
+		is.Equal(t, http.StatusNotFound, serve(mux, "/styles/", nil).Code)
+	})
+
+	t.Run("serves default robots.txt and manifest.json", func(t *testing.T) {
+		assets, mux := newAssets()
+
+		rec := serve(mux, "/robots.txt", nil)
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, "User-agent: *\nAllow: /\n", rec.Body.String())
+
+		rec = serve(mux, "/manifest.json", nil)
+		is.Equal(t, http.StatusOK, rec.Code)
+		is.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		is.True(t, strings.Contains(rec.Body.String(), `"name":"Glue"`))
+		is.True(t, strings.Contains(rec.Body.String(), assets.Path("/web-app-manifest-192x192.png")))
+	})
+}
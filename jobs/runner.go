@@ -4,14 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
+	"maragu.dev/errors"
 	"maragu.dev/goqite"
 	"maragu.dev/goqite/jobs"
+
+	"maragu.dev/glue/health"
 )
 
 type Runner = jobs.Runner
@@ -31,6 +38,63 @@ func NewRunner(opts NewRunnerOpts) *Runner {
 	})
 }
 
+// Start the runner like [Runner.Start], blocking until ctx is done.
+// If reg is not nil, a readiness check is registered under name, which fails when the runner is not running.
+// Starting again with the same name replaces the check, so it reflects the latest runner.
+func Start(ctx context.Context, r *Runner, reg *health.Registry, name string) {
+	var running atomic.Bool
+
+	if reg != nil {
+		reg.RegisterReady(name, func(ctx context.Context) error {
+			if !running.Load() {
+				return errors.New("job runner not running")
+			}
+			return nil
+		})
+	}
+
+	running.Store(true)
+	defer running.Store(false)
+
+	r.Start(ctx)
+}
+
+// RegisterQueueDepthMetric registers the jobs.queue.depth gauge, with the number of messages in each queue
+// in the goqite table of db, observed when metrics are collected.
+// Call it once after connecting to the database, for example with [maragu.dev/glue/sql.Helper.DB].
+func RegisterQueueDepthMetric(db *sql.DB) error {
+	meter := otel.Meter("maragu.dev/glue/jobs")
+	depth, err := meter.Int64ObservableGauge("jobs.queue.depth",
+		metric.WithDescription("Number of messages in the job queue, including ones being processed."),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		rows, err := db.QueryContext(ctx, `select queue, count(*) from goqite group by queue`)
+		if err != nil {
+			return errors.Wrap(err, "error querying queue depth")
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		for rows.Next() {
+			var queue string
+			var count int64
+			if err := rows.Scan(&queue, &count); err != nil {
+				return errors.Wrap(err, "error scanning queue depth")
+			}
+			o.ObserveInt64(depth, count, metric.WithAttributes(attribute.String("jobs.queue", queue)))
+		}
+		return rows.Err()
+	}, depth)
+
+	return err
+}
+
 func Create(ctx context.Context, q *goqite.Queue, name string, m Message) error {
 	m = wrapWithTrace(ctx, m)
 	_, err := jobs.Create(ctx, q, name, m)
@@ -81,8 +145,17 @@ type tracedMessage struct {
 // WithTracing wraps a [Func] with OpenTelemetry tracing and trace context propagation.
 // It extracts trace context from tracedMessage if present and creates a span with proper
 // parent-child relationships. The wrapped function receives the raw payload bytes.
+// The job duration is recorded in the jobs.duration histogram, with the operation name and outcome.
 func WithTracing(operationName string, fn Func) Func {
 	tracer := otel.Tracer("maragu.dev/glue/jobs")
+	duration, err := otel.Meter("maragu.dev/glue/jobs").Float64Histogram("jobs.duration",
+		metric.WithDescription("Duration of jobs."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		// Metrics shouldn't stop jobs from running, so just don't record the duration
+		duration = noop.Float64Histogram{}
+	}
 
 	return func(ctx context.Context, m []byte) error {
 		// Try to unmarshal as tracedMessage first to extract trace context
@@ -102,7 +175,19 @@ func WithTracing(operationName string, fn Func) Func {
 		)
 		defer span.End()
 
-		if err := fn(ctx, m); err != nil {
+		start := time.Now()
+		err := fn(ctx, m)
+
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			attribute.String("jobs.name", operationName),
+			attribute.String("jobs.outcome", outcome),
+		))
+
+		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "job failed")
 			return err
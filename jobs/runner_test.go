@@ -3,15 +3,21 @@ package jobs_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"maragu.dev/is"
 
+	"maragu.dev/glue/health"
 	"maragu.dev/glue/jobs"
+	"maragu.dev/glue/oteltest"
+	"maragu.dev/glue/sqlitetest"
 )
 
 type TestPayload struct {
@@ -120,4 +126,93 @@ func TestWithTracing(t *testing.T) {
 		span := trace.SpanFromContext(receivedCtx)
 		is.True(t, span.SpanContext().IsValid())
 	})
+
+	t.Run("records job duration with name and outcome", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+
+		handler := jobs.WithTracing("test-operation", func(ctx context.Context, m []byte) error {
+			return errors.New("oh no")
+		})
+
+		err := handler(t.Context(), []byte("{}"))
+		is.True(t, err != nil)
+
+		m := oteltest.GetMetric(t, r, "jobs.duration")
+		h, ok := m.Data.(metricdata.Histogram[float64])
+		is.True(t, ok)
+		is.Equal(t, 1, len(h.DataPoints))
+		name, _ := h.DataPoints[0].Attributes.Value("jobs.name")
+		is.Equal(t, "test-operation", name.AsString())
+		outcome, _ := h.DataPoints[0].Attributes.Value("jobs.outcome")
+		is.Equal(t, "failure", outcome.AsString())
+	})
+}
+
+func TestRegisterQueueDepthMetric(t *testing.T) {
+	t.Run("observes the number of messages in each queue", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+		h := sqlitetest.NewHelper(t)
+
+		// The goqite schema is part of app migrations, not glue's
+		err := h.Exec(t.Context(), `create table goqite (id text primary key default ('m_' || lower(hex(randomblob(16)))),
+			created text not null default (strftime('%Y-%m-%dT%H:%M:%fZ')), updated text not null default (strftime('%Y-%m-%dT%H:%M:%fZ')),
+			queue text not null, body blob not null, timeout text not null default (strftime('%Y-%m-%dT%H:%M:%fZ')),
+			received integer not null default 0, priority integer not null default 0) strict`)
+		is.NotError(t, err)
+
+		err = jobs.Create(t.Context(), h.JobsQ, "test", jobs.Message{Body: []byte("{}")})
+		is.NotError(t, err)
+		err = jobs.Create(t.Context(), h.JobsQ, "test", jobs.Message{Body: []byte("{}")})
+		is.NotError(t, err)
+
+		err = jobs.RegisterQueueDepthMetric(h.DB.DB)
+		is.NotError(t, err)
+
+		m := oteltest.GetMetric(t, r, "jobs.queue.depth")
+		g, ok := m.Data.(metricdata.Gauge[int64])
+		is.True(t, ok)
+		is.Equal(t, 1, len(g.DataPoints))
+		is.Equal(t, int64(2), g.DataPoints[0].Value)
+		queue, _ := g.DataPoints[0].Attributes.Value("jobs.queue")
+		is.Equal(t, "jobs", queue.AsString())
+	})
+}
+
+func TestStart(t *testing.T) {
+	t.Run("registers a readiness check that passes while the runner is running", func(t *testing.T) {
+		h := sqlitetest.NewHelper(t)
+		r := jobs.NewRunner(jobs.NewRunnerOpts{Queue: h.JobsQ})
+		reg := health.NewRegistry(health.NewRegistryOptions{})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		done := make(chan struct{})
+		go func() {
+			jobs.Start(ctx, r, reg, "jobs")
+			close(done)
+		}()
+
+		for reg.Ready(t.Context()).Status != health.StatusOK {
+			time.Sleep(time.Millisecond)
+		}
+
+		cancel()
+		<-done
+
+		report := reg.Ready(t.Context())
+		is.Equal(t, health.StatusError, report.Status)
+		is.Equal(t, "job runner not running", report.Checks[0].Error)
+	})
+
+	t.Run("registers the readiness check only once when started again", func(t *testing.T) {
+		h := sqlitetest.NewHelper(t)
+		r := jobs.NewRunner(jobs.NewRunnerOpts{Queue: h.JobsQ})
+		reg := health.NewRegistry(health.NewRegistryOptions{})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		jobs.Start(ctx, r, reg, "jobs")
+		jobs.Start(ctx, r, reg, "jobs")
+
+		is.Equal(t, 1, len(reg.Ready(t.Context()).Checks))
+	})
 }
@@ -15,6 +15,9 @@ type NewLoggerOptions struct {
 	Level  slog.Level
 	NoTime bool
 
+	// LevelVar is used instead of Level if set, so the level can be changed while the logger is in use.
+	LevelVar *slog.LevelVar
+
 	// W is where logs are written. It defaults to [os.Stderr] when nil.
 	W io.Writer
 }
@@ -36,15 +39,20 @@ func NewLogger(opts NewLoggerOptions) *slog.Logger {
 		}
 	}
 
+	var level slog.Leveler = opts.Level
+	if opts.LevelVar != nil {
+		level = opts.LevelVar
+	}
+
 	var handler slog.Handler
 	if opts.JSON {
 		handler = slog.NewJSONHandler(opts.W, &slog.HandlerOptions{
-			Level:       opts.Level,
+			Level:       level,
 			ReplaceAttr: replaceAttr,
 		})
 	} else {
 		handler = slog.NewTextHandler(opts.W, &slog.HandlerOptions{
-			Level:       opts.Level,
+			Level:       level,
 			ReplaceAttr: replaceAttr,
 		})
 	}
@@ -138,6 +138,19 @@ func TestNewLogger(t *testing.T) {
 		_, hasTime := entry["time"]
 		is.True(t, hasTime)
 	})
+
+	t.Run("uses the level from LevelVar, which can be changed while in use", func(t *testing.T) {
+		var buf bytes.Buffer
+		var level slog.LevelVar
+		logger := NewLogger(NewLoggerOptions{JSON: true, LevelVar: &level, W: &buf})
+
+		logger.Debug("hello")
+		is.Equal(t, 0, buf.Len())
+
+		level.Set(slog.LevelDebug)
+		logger.Debug("hello")
+		is.True(t, buf.Len() > 0)
+	})
 }
 
 // newSpan starts a recording span backed by [oteltest.NewSpanRecorder] and returns the context
@@ -2,6 +2,8 @@ package model
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -46,3 +48,103 @@ func (p Permission) String() string {
 }
 
 var _ fmt.Stringer = Permission("")
+
+// User is the part of an app's user that glue needs for authentication.
+type User struct {
+	ID     UserID
+	Name   string
+	Email  EmailAddress
+	Active bool
+}
+
+// AccessTokenID identifies an [AccessToken].
+type AccessTokenID ID
+
+// String satisfies [fmt.Stringer].
+func (i AccessTokenID) String() string {
+	return string(i)
+}
+
+var _ fmt.Stringer = AccessTokenID("")
+
+// AccessToken is a personal access token for a user, used for bearer authentication in API requests.
+// Its Scopes limit the user's permissions while authenticated with the token.
+type AccessToken struct {
+	ID       AccessTokenID
+	UserID   UserID
+	Name     string
+	Scopes   []Permission
+	Created  Time
+	LastUsed *Time
+	Expires  *Time
+}
+
+// RolePermissions maps roles to the permissions they grant, declared in code.
+type RolePermissions map[Role][]Permission
+
+// Permissions granted by the given roles, deduplicated and sorted. Roles not in the mapping grant nothing.
+func (rp RolePermissions) Permissions(roles ...Role) []Permission {
+	permissions := []Permission{}
+	for _, r := range roles {
+		for _, p := range rp[r] {
+			if !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	slices.Sort(permissions)
+	return permissions
+}
+
+// Roles in the mapping, sorted.
+func (rp RolePermissions) Roles() []Role {
+	return slices.Sorted(maps.Keys(rp))
+}
+
+// Has returns whether the role is in the mapping.
+func (rp RolePermissions) Has(r Role) bool {
+	_, ok := rp[r]
+	return ok
+}
+
+// SessionID identifies a [Session].
+type SessionID ID
+
+// String satisfies [fmt.Stringer].
+func (i SessionID) String() string {
+	return string(i)
+}
+
+var _ fmt.Stringer = SessionID("")
+
+// Session is metadata about a logged-in session of a user, so users can see where they're logged in.
+// Browser and OS are parsed from the user agent.
+type Session struct {
+	ID       SessionID
+	UserID   UserID
+	IP       string
+	Browser  string
+	OS       string
+	Created  Time
+	LastSeen Time
+}
+
+// ImpersonationID identifies an audit record of an admin impersonating a user.
+type ImpersonationID ID
+
+// String satisfies [fmt.Stringer].
+func (i ImpersonationID) String() string {
+	return string(i)
+}
+
+var _ fmt.Stringer = ImpersonationID("")
+
+// Impersonation is an audit record of an admin seeing the app as another user.
+// Stopped is nil while the impersonation is ongoing, or if it was never stopped explicitly.
+type Impersonation struct {
+	ID          ImpersonationID
+	AdminUserID UserID
+	UserID      UserID
+	Started     Time
+	Stopped     *Time
+}
@@ -29,3 +29,23 @@ func TestRole_Pretty(t *testing.T) {
 		}
 	})
 }
+
+func TestRolePermissions(t *testing.T) {
+	rp := model.RolePermissions{
+		"admin":  {"write", "read", "admin"},
+		"editor": {"write", "read"},
+		"viewer": {"read"},
+	}
+
+	t.Run("returns sorted, deduplicated permissions for the given roles", func(t *testing.T) {
+		is.EqualSlice(t, []model.Permission{"read", "write"}, rp.Permissions("viewer", "editor"))
+	})
+
+	t.Run("ignores unknown roles", func(t *testing.T) {
+		is.EqualSlice(t, []model.Permission{}, rp.Permissions("nobody"))
+	})
+
+	t.Run("returns sorted roles", func(t *testing.T) {
+		is.EqualSlice(t, []model.Role{"admin", "editor", "viewer"}, rp.Roles())
+	})
+}
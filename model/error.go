@@ -4,11 +4,14 @@ package model
 type Error string
 
 const (
-	ErrorEmailConflict = Error("email conflict")
-	ErrorTokenExpired  = Error("token expired")
-	ErrorTokenNotFound = Error("token not found")
-	ErrorUserInactive  = Error("user inactive")
-	ErrorUserNotFound  = Error("user not found")
+	ErrorEmailConflict                 = Error("email conflict")
+	ErrorIdempotencyKeyInProgress      = Error("idempotency key in progress")
+	ErrorIdempotencyKeyRequestMismatch = Error("idempotency key request mismatch")
+	ErrorSessionNotFound               = Error("session not found")
+	ErrorTokenExpired                  = Error("token expired")
+	ErrorTokenNotFound                 = Error("token not found")
+	ErrorUserInactive                  = Error("user inactive")
+	ErrorUserNotFound                  = Error("user not found")
 )
 
 // Error satisfies [error].
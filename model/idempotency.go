@@ -0,0 +1,8 @@
+package model
+
+// IdempotentResponse is the stored response to the first request with an idempotency key, replayed for repeated requests.
+type IdempotentResponse struct {
+	Status int
+	Header map[string][]string
+	Body   []byte
+}
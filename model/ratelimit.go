@@ -0,0 +1,28 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket that allows bursts of up to Burst requests, and refills one token every Every.
+type RateLimit struct {
+	Burst int
+	Every time.Duration
+}
+
+// Take a token from a bucket with the given tokens, last updated at the given time.
+// A new bucket starts full, with Burst tokens.
+// Returns the tokens left, whether a token was taken, and if not, how long until the next token is available.
+func (l RateLimit) Take(tokens float64, updated, now time.Time) (float64, bool, time.Duration) {
+	if elapsed := now.Sub(updated); elapsed > 0 && l.Every > 0 {
+		tokens = math.Min(float64(l.Burst), tokens+float64(elapsed)/float64(l.Every))
+	}
+
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+
+	wait := time.Duration(math.Ceil((1 - tokens) * float64(l.Every)))
+	return tokens, false, wait
+}
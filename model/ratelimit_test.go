@@ -0,0 +1,45 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+)
+
+func TestRateLimit_Take(t *testing.T) {
+	l := model.RateLimit{Burst: 2, Every: time.Second}
+	now := time.Now()
+
+	t.Run("takes tokens until the bucket is empty", func(t *testing.T) {
+		tokens, ok, _ := l.Take(2, now, now)
+		is.True(t, ok)
+		is.Equal(t, 1.0, tokens)
+
+		tokens, ok, _ = l.Take(tokens, now, now)
+		is.True(t, ok)
+		is.Equal(t, 0.0, tokens)
+
+		_, ok, wait := l.Take(tokens, now, now)
+		is.True(t, !ok)
+		is.Equal(t, time.Second, wait)
+	})
+
+	t.Run("refills tokens over time, up to the burst", func(t *testing.T) {
+		tokens, ok, _ := l.Take(0, now, now.Add(500*time.Millisecond))
+		is.True(t, !ok)
+		is.Equal(t, 0.5, tokens)
+
+		tokens, ok, _ = l.Take(0, now, now.Add(time.Hour))
+		is.True(t, ok)
+		is.Equal(t, 1.0, tokens)
+	})
+
+	t.Run("returns the wait until the next token", func(t *testing.T) {
+		_, ok, wait := l.Take(0.25, now, now)
+		is.True(t, !ok)
+		is.Equal(t, 750*time.Millisecond, wait)
+	})
+}
@@ -7,6 +7,8 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
@@ -31,6 +33,48 @@ func NewSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
 	return sr
 }
 
+// NewMetricReader for testing.
+// It sets up a [sdkmetric.ManualReader] in the global [sdkmetric.MeterProvider] for the duration of the test.
+// Instruments must be created after calling it, so create the instrumented components afterwards.
+// It is not safe for use with parallel tests, as it mutates the global meter provider.
+func NewMetricReader(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+
+	r := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))
+
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(mp)
+
+	t.Cleanup(func() {
+		_ = mp.Shutdown(context.WithoutCancel(t.Context()))
+		otel.SetMeterProvider(previous)
+	})
+
+	return r
+}
+
+// GetMetric collects metrics from the reader and returns the one with the given name, failing the test if it's not found.
+func GetMetric(t *testing.T, r *sdkmetric.ManualReader, name string) metricdata.Metrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := r.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+
+	t.Fatalf("metric %v not found", name)
+	return metricdata.Metrics{}
+}
+
 // HasAttribute checks whether the given [attribute.KeyValue] is present in the slice, matching both key and value.
 func HasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
 	for _, attr := range attrs {
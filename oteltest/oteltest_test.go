@@ -5,6 +5,7 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"maragu.dev/is"
 
 	"maragu.dev/glue/oteltest"
@@ -74,3 +75,18 @@ func TestHasAttributeKey(t *testing.T) {
 		is.True(t, !oteltest.HasAttributeKey(nil, "foo"))
 	})
 }
+
+func TestNewMetricReader(t *testing.T) {
+	t.Run("collects metrics from the global meter provider", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+
+		counter, err := otel.Meter("test").Int64Counter("test.counter")
+		is.NotError(t, err)
+		counter.Add(t.Context(), 2)
+
+		m := oteltest.GetMetric(t, r, "test.counter")
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		is.True(t, ok)
+		is.Equal(t, int64(2), sum.DataPoints[0].Value)
+	})
+}
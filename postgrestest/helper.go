@@ -99,7 +99,7 @@ func connect(t *testing.T, name string) (*sql.Helper, func(t *testing.T)) {
 		t.Fatal(err)
 	}
 	return h, func(t *testing.T) {
-		if err := h.DB.Close(); err != nil {
+		if err := h.Close(); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -13,48 +13,74 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
 	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/glue/health"
 )
 
 type Bucket struct {
 	Client     *s3.Client
 	attributes []attribute.KeyValue
+	duration   metric.Float64Histogram
 	name       string
 	tracer     trace.Tracer
 }
 
 type NewBucketOptions struct {
 	Config    aws.Config
+	Health    *health.Registry
 	Name      string
 	PathStyle bool
 }
 
+// NewBucket with the given options.
+// Operations are traced, and their durations recorded in the s3.operation.duration histogram.
+// If the Health registry is given, [Bucket.Ping] is registered in it as the "s3:" plus bucket name readiness check.
 func NewBucket(opts NewBucketOptions) *Bucket {
 	if opts.Name == "" {
 		panic("bucket name must not be empty")
 	}
 
+	duration, err := otel.Meter("maragu.dev/glue/s3").Float64Histogram("s3.operation.duration",
+		metric.WithDescription("Duration of S3 operations."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		// Metrics shouldn't stop the bucket from working, so just don't record durations
+		duration = noop.Float64Histogram{}
+	}
+
 	client := s3.NewFromConfig(opts.Config, func(o *s3.Options) {
 		o.UsePathStyle = opts.PathStyle
 		o.DisableLogOutputChecksumValidationSkipped = true
 	})
 
-	return &Bucket{
+	b := &Bucket{
 		Client: client,
 		attributes: []attribute.KeyValue{
 			semconv.AWSS3Bucket(opts.Name),
 			semconv.CloudRegion(opts.Config.Region),
 		},
-		name:   opts.Name,
-		tracer: otel.Tracer("maragu.dev/glue/s3"),
+		duration: duration,
+		name:     opts.Name,
+		tracer:   otel.Tracer("maragu.dev/glue/s3"),
+	}
+
+	if opts.Health != nil {
+		opts.Health.RegisterReady("s3:"+opts.Name, b.Ping)
 	}
+
+	return b
 }
 
 // Put an object under key with the given contentType.
 func (b *Bucket) Put(ctx context.Context, key, contentType string, body io.ReadSeeker) error {
 	ctx, span := b.operationTracerStart(ctx, "s3.put", key)
 	defer span.End()
+	defer b.recordDuration(ctx, "s3.put", time.Now())
 
 	_, err := b.Client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:      &b.name,
@@ -76,6 +102,7 @@ func (b *Bucket) Put(ctx context.Context, key, contentType string, body io.ReadS
 func (b *Bucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
 	ctx, span := b.operationTracerStart(ctx, "s3.get", key)
 	defer span.End()
+	defer b.recordDuration(ctx, "s3.get", time.Now())
 
 	getObjectOutput, err := b.Client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: &b.name,
@@ -99,6 +126,7 @@ func (b *Bucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
 func (b *Bucket) Exists(ctx context.Context, key string) (bool, error) {
 	ctx, span := b.operationTracerStart(ctx, "s3.exists", key)
 	defer span.End()
+	defer b.recordDuration(ctx, "s3.exists", time.Now())
 
 	_, err := b.Client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: &b.name,
@@ -122,6 +150,7 @@ func (b *Bucket) Exists(ctx context.Context, key string) (bool, error) {
 func (b *Bucket) Delete(ctx context.Context, key string) error {
 	ctx, span := b.operationTracerStart(ctx, "s3.delete", key)
 	defer span.End()
+	defer b.recordDuration(ctx, "s3.delete", time.Now())
 
 	_, err := b.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: &b.name,
@@ -136,6 +165,28 @@ func (b *Bucket) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Ping checks that the bucket exists and is accessible, using a HEAD request on the bucket.
+// It can be used as a [maragu.dev/glue/health.Check].
+func (b *Bucket) Ping(ctx context.Context) error {
+	ctx, span := b.tracer.Start(ctx, "s3.ping",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(b.attributes...),
+	)
+	defer span.End()
+	defer b.recordDuration(ctx, "s3.ping", time.Now())
+
+	_, err := b.Client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: &b.name,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "ping failed")
+		return err
+	}
+
+	return nil
+}
+
 func (b *Bucket) GetPresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
 	c := s3.NewPresignClient(b.Client, s3.WithPresignExpires(expires))
 
@@ -152,6 +203,7 @@ func (b *Bucket) GetPresignedURL(ctx context.Context, key string, expires time.D
 func (b *Bucket) List(ctx context.Context, prefix string, maxKeys int) ([]string, error) {
 	ctx, span := b.operationTracerStart(ctx, "s3.list", prefix)
 	defer span.End()
+	defer b.recordDuration(ctx, "s3.list", time.Now())
 
 	listObjectsOutput, err := b.Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
 		Bucket:  &b.name,
@@ -172,6 +224,12 @@ func (b *Bucket) List(ctx context.Context, prefix string, maxKeys int) ([]string
 	return keys, nil
 }
 
+// recordDuration since start for the given operation.
+func (b *Bucket) recordDuration(ctx context.Context, operation string, start time.Time) {
+	b.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(b.attributes...),
+		metric.WithAttributes(attribute.String("s3.operation", operation)))
+}
+
 func (b *Bucket) operationTracerStart(ctx context.Context, operation, key string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	allOpts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
 	"maragu.dev/is"
@@ -42,6 +43,22 @@ func TestBucket(t *testing.T) {
 		is.NotError(t, err)
 	})
 
+	t.Run("records operation durations", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+
+		b := s3test.NewBucket(t)
+
+		err := b.Put(t.Context(), "test", "text/plain", strings.NewReader("hello"))
+		is.NotError(t, err)
+
+		m := oteltest.GetMetric(t, r, "s3.operation.duration")
+		h, ok := m.Data.(metricdata.Histogram[float64])
+		is.True(t, ok)
+		is.Equal(t, 1, len(h.DataPoints))
+		operation, _ := h.DataPoints[0].Attributes.Value("s3.operation")
+		is.Equal(t, "s3.put", operation.AsString())
+	})
+
 	t.Run("records a span with the bucket and key when putting an object", func(t *testing.T) {
 		sr := oteltest.NewSpanRecorder(t)
 
@@ -163,6 +180,15 @@ func TestBucket_Exists(t *testing.T) {
 	})
 }
 
+func TestBucket_Ping(t *testing.T) {
+	t.Run("returns no error when the bucket exists", func(t *testing.T) {
+		b := s3test.NewBucket(t)
+
+		err := b.Ping(t.Context())
+		is.NotError(t, err)
+	})
+}
+
 func TestBucket_GetPresignedURL(t *testing.T) {
 	t.Run("returns a presigned URL", func(t *testing.T) {
 		b := s3test.NewBucket(t)
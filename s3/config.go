@@ -0,0 +1,20 @@
+package s3
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Config for [NewBucket], to load with [maragu.dev/glue/config.Load]. AWS credentials and region are loaded by the AWS SDK itself.
+type Config struct {
+	BucketName string `env:"BUCKET_NAME,required"`
+	PathStyle  bool   `env:"BUCKET_PATH_STYLE" default:"false"`
+}
+
+// BucketOptions from the config.
+func (c Config) BucketOptions(awsConfig aws.Config) NewBucketOptions {
+	return NewBucketOptions{
+		Config:    awsConfig,
+		Name:      c.BucketName,
+		PathStyle: c.PathStyle,
+	}
+}
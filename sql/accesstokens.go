@@ -0,0 +1,149 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+// accessTokenPrefix makes access tokens recognizable, for example by secret scanners.
+const accessTokenPrefix = "glue_pat_"
+
+// accessTokenLastUsedInterval is how often the last used time of an access token is updated at most,
+// so authenticating many requests with the same token doesn't write on every request.
+const accessTokenLastUsedInterval = time.Minute
+
+type accessTokenRow struct {
+	ID       model.AccessTokenID
+	UserID   model.UserID `db:"user_id"`
+	Name     string
+	Scopes   string
+	Created  model.Time
+	LastUsed *model.Time `db:"last_used"`
+	Expires  *model.Time
+}
+
+func (r accessTokenRow) toModel() model.AccessToken {
+	var scopes []model.Permission
+	for _, s := range strings.Fields(r.Scopes) {
+		scopes = append(scopes, model.Permission(s))
+	}
+
+	return model.AccessToken{
+		ID:       r.ID,
+		UserID:   r.UserID,
+		Name:     r.Name,
+		Scopes:   scopes,
+		Created:  r.Created,
+		LastUsed: r.LastUsed,
+		Expires:  r.Expires,
+	}
+}
+
+// CreateAccessToken for the given user, with a name to recognize it by and scopes that limit the user's permissions.
+// If lifetime is zero, the token doesn't expire.
+// The returned token string is only available here, because only a hash of it is stored.
+func (h *Helper) CreateAccessToken(ctx context.Context, userID model.UserID, name string, scopes []model.Permission, lifetime time.Duration) (model.AccessToken, string, error) {
+	var scopeStrings []string
+	for _, s := range scopes {
+		if s == "" || strings.ContainsAny(string(s), " \t\n") {
+			return model.AccessToken{}, "", errors.Newf("invalid scope %q", s)
+		}
+		scopeStrings = append(scopeStrings, string(s))
+	}
+
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	now := model.Now()
+	row := accessTokenRow{
+		ID:      model.AccessTokenID("at_" + hex.EncodeToString(id)),
+		UserID:  userID,
+		Name:    name,
+		Scopes:  strings.Join(scopeStrings, " "),
+		Created: now,
+	}
+	if lifetime != 0 {
+		row.Expires = &model.Time{T: now.T.Add(lifetime)}
+	}
+
+	token := accessTokenPrefix + rand.Text()
+
+	query := `
+		insert into access_tokens (id, user_id, name, hash, scopes, created, expires)
+		values ($1, $2, $3, $4, $5, $6, $7)`
+	if err := h.Exec(ctx, query, row.ID, row.UserID, row.Name, hashToken(token), row.Scopes, row.Created, row.Expires); err != nil {
+		return model.AccessToken{}, "", errors.Wrap(err, "error inserting access token")
+	}
+
+	return row.toModel(), token, nil
+}
+
+// GetAccessTokens for the given user, newest first.
+func (h *Helper) GetAccessTokens(ctx context.Context, userID model.UserID) ([]model.AccessToken, error) {
+	var rows []accessTokenRow
+	query := `
+		select id, user_id, name, scopes, created, last_used, expires
+		from access_tokens where user_id = $1 order by created desc, id`
+	if err := h.Select(ctx, &rows, query, userID); err != nil {
+		return nil, errors.Wrap(err, "error getting access tokens")
+	}
+
+	tokens := []model.AccessToken{}
+	for _, r := range rows {
+		tokens = append(tokens, r.toModel())
+	}
+	return tokens, nil
+}
+
+// RevokeAccessToken with the given ID belonging to the given user, by deleting it.
+// Returns [model.ErrorTokenNotFound] if the user has no such token.
+func (h *Helper) RevokeAccessToken(ctx context.Context, userID model.UserID, id model.AccessTokenID) error {
+	var deletedID model.AccessTokenID
+	query := `delete from access_tokens where id = $1 and user_id = $2 returning id`
+	if err := h.Get(ctx, &deletedID, query, id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrorTokenNotFound
+		}
+		return errors.Wrap(err, "error deleting access token")
+	}
+	return nil
+}
+
+// AuthenticateAccessToken returns the access token for the given token string, and updates its last used time.
+// Returns [model.ErrorTokenNotFound] if the token doesn't exist or has been revoked, and [model.ErrorTokenExpired] if it has expired.
+func (h *Helper) AuthenticateAccessToken(ctx context.Context, token string) (model.AccessToken, error) {
+	if !strings.HasPrefix(token, accessTokenPrefix) {
+		return model.AccessToken{}, model.ErrorTokenNotFound
+	}
+
+	var row accessTokenRow
+	query := `select id, user_id, name, scopes, created, last_used, expires from access_tokens where hash = $1`
+	if err := h.Get(ctx, &row, query, hashToken(token)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.AccessToken{}, model.ErrorTokenNotFound
+		}
+		return model.AccessToken{}, errors.Wrap(err, "error getting access token")
+	}
+
+	now := model.Now()
+	if row.Expires != nil && row.Expires.T.Before(now.T) {
+		return model.AccessToken{}, model.ErrorTokenExpired
+	}
+
+	if row.LastUsed == nil || now.T.Sub(row.LastUsed.T) >= accessTokenLastUsedInterval {
+		if err := h.Exec(ctx, `update access_tokens set last_used = $1 where id = $2`, now, row.ID); err != nil {
+			return model.AccessToken{}, errors.Wrap(err, "error updating access token last used time")
+		}
+		row.LastUsed = &now
+	}
+
+	return row.toModel(), nil
+}
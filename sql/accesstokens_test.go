@@ -0,0 +1,67 @@
+package sql_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestHelper_AccessTokens(t *testing.T) {
+	internaltesting.Run(t, "creates, authenticates, lists, and revokes tokens", func(t *testing.T, h *sql.Helper) {
+		at, token, err := h.CreateAccessToken(t.Context(), "u_1", "CLI", []model.Permission{"read", "write"}, 0)
+		is.NotError(t, err)
+		is.True(t, strings.HasPrefix(token, "glue_pat_"))
+		is.True(t, strings.HasPrefix(at.ID.String(), "at_"))
+		is.Equal(t, "CLI", at.Name)
+		is.True(t, at.Expires == nil)
+
+		authenticated, err := h.AuthenticateAccessToken(t.Context(), token)
+		is.NotError(t, err)
+		is.Equal(t, at.ID, authenticated.ID)
+		is.Equal(t, model.UserID("u_1"), authenticated.UserID)
+		is.EqualSlice(t, []model.Permission{"read", "write"}, authenticated.Scopes)
+		is.True(t, authenticated.LastUsed != nil)
+
+		tokens, err := h.GetAccessTokens(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.Equal(t, 1, len(tokens))
+		is.Equal(t, at.ID, tokens[0].ID)
+		is.True(t, tokens[0].LastUsed != nil)
+
+		err = h.RevokeAccessToken(t.Context(), "u_1", at.ID)
+		is.NotError(t, err)
+
+		_, err = h.AuthenticateAccessToken(t.Context(), token)
+		is.Error(t, model.ErrorTokenNotFound, err)
+	})
+
+	internaltesting.Run(t, "does not revoke another user's token", func(t *testing.T, h *sql.Helper) {
+		at, token, err := h.CreateAccessToken(t.Context(), "u_1", "CLI", nil, 0)
+		is.NotError(t, err)
+
+		err = h.RevokeAccessToken(t.Context(), "u_2", at.ID)
+		is.Error(t, model.ErrorTokenNotFound, err)
+
+		_, err = h.AuthenticateAccessToken(t.Context(), token)
+		is.NotError(t, err)
+	})
+
+	internaltesting.Run(t, "errors if the token has expired", func(t *testing.T, h *sql.Helper) {
+		_, token, err := h.CreateAccessToken(t.Context(), "u_1", "CLI", nil, -time.Minute)
+		is.NotError(t, err)
+
+		_, err = h.AuthenticateAccessToken(t.Context(), token)
+		is.Error(t, model.ErrorTokenExpired, err)
+	})
+
+	internaltesting.Run(t, "errors if the token does not exist", func(t *testing.T, h *sql.Helper) {
+		_, err := h.AuthenticateAccessToken(t.Context(), "glue_pat_doesnotexist")
+		is.Error(t, model.ErrorTokenNotFound, err)
+	})
+}
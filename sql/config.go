@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"log/slog"
+	"time"
+
+	"maragu.dev/errors"
+)
+
+// Config for [NewHelper], to load with [maragu.dev/glue/config.Load].
+// Exactly one of DATABASE_URL (PostgreSQL) or DATABASE_PATH (SQLite) must be set.
+type Config struct {
+	ConnectionMaxIdleTime time.Duration `env:"DATABASE_CONNECTION_MAX_IDLE_TIME" default:"10m"`
+	ConnectionMaxLifetime time.Duration `env:"DATABASE_CONNECTION_MAX_LIFETIME" default:"1h"`
+	JobQueueTimeout       time.Duration `env:"JOB_QUEUE_TIMEOUT" default:"5s"`
+	MaxIdleConnections    int           `env:"DATABASE_MAX_IDLE_CONNECTIONS" default:"10"`
+	MaxOpenConnections    int           `env:"DATABASE_MAX_OPEN_CONNECTIONS" default:"10"`
+	Path                  string        `env:"DATABASE_PATH"`
+	URL                   string        `env:"DATABASE_URL,secret"`
+}
+
+// Validate satisfies the validator interface of [maragu.dev/glue/config.Load].
+func (c *Config) Validate() error {
+	if (c.Path == "") == (c.URL == "") {
+		return errors.New("exactly one of DATABASE_PATH and DATABASE_URL must be set")
+	}
+	return nil
+}
+
+// HelperOptions from the config.
+func (c Config) HelperOptions(log *slog.Logger) NewHelperOptions {
+	return NewHelperOptions{
+		JobQueue: JobQueueOptions{
+			Timeout: c.JobQueueTimeout,
+		},
+		Log: log,
+		Postgres: PostgresOptions{
+			ConnectionMaxIdleTime: c.ConnectionMaxIdleTime,
+			ConnectionMaxLifetime: c.ConnectionMaxLifetime,
+			MaxIdleConnections:    c.MaxIdleConnections,
+			MaxOpenConnections:    c.MaxOpenConnections,
+			URL:                   c.URL,
+		},
+		SQLite: SQLiteOptions{
+			Path: c.Path,
+		},
+	}
+}
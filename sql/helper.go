@@ -12,10 +12,14 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
 	"go.opentelemetry.io/otel/trace"
 	"maragu.dev/errors"
 	"maragu.dev/goqite"
+
+	"maragu.dev/glue/health"
 )
 
 type Helper struct {
@@ -28,12 +32,16 @@ type Helper struct {
 	log                   *slog.Logger
 	maxIdleConnections    int
 	maxOpenConnections    int
+	meter                 metric.Meter
 	path                  string
+	poolMetrics           metric.Registration
+	queryDuration         metric.Float64Histogram
 	tracer                trace.Tracer
 	url                   string
 }
 
 type NewHelperOptions struct {
+	Health   *health.Registry
 	JobQueue JobQueueOptions
 	Log      *slog.Logger
 	Postgres PostgresOptions
@@ -59,24 +67,46 @@ type JobQueueOptions struct {
 // NewHelper with the given options.
 // If no logger is provided, logs are discarded.
 // For documentation on OTel spans and attributes, see https://opentelemetry.io/docs/specs/semconv/database/database-spans/
+// Query durations and connection pool stats are recorded as metrics, see https://opentelemetry.io/docs/specs/semconv/database/database-metrics/
+// If the Health registry is given, [Helper.Ping] is registered in it as the "sql" readiness check.
 func NewHelper(opts NewHelperOptions) *Helper {
 	if opts.Log == nil {
 		opts.Log = slog.New(slog.DiscardHandler)
 	}
 
-	return &Helper{
+	meter := otel.Meter("maragu.dev/glue/sql")
+	queryDuration, err := meter.Float64Histogram("db.client.operation.duration",
+		metric.WithDescription("Duration of database client operations."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		opts.Log.Error("Error creating query duration histogram, not recording durations", "error", err)
+		queryDuration = noop.Float64Histogram{}
+	}
+
+	h := &Helper{
 		connectionMaxIdleTime: opts.Postgres.ConnectionMaxIdleTime,
 		connectionMaxLifetime: opts.Postgres.ConnectionMaxLifetime,
 		jobQueueTimeout:       opts.JobQueue.Timeout,
 		log:                   opts.Log,
 		maxIdleConnections:    opts.Postgres.MaxIdleConnections,
 		maxOpenConnections:    opts.Postgres.MaxOpenConnections,
+		meter:                 meter,
 		path:                  opts.SQLite.Path,
+		queryDuration:         queryDuration,
 		tracer:                otel.Tracer("maragu.dev/glue/sql"),
 		url:                   opts.Postgres.URL,
 	}
+
+	if opts.Health != nil {
+		opts.Health.RegisterReady("sql", h.Ping)
+	}
+
+	return h
 }
 
+// Connect to the database. Connecting again replaces the previous connection pool in the [Helper],
+// without closing it, so call [Helper.Close] first if needed.
 func (h *Helper) Connect(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -134,6 +164,10 @@ func (h *Helper) Connect(ctx context.Context) error {
 		panic("neither postgres url nor sqlite path given")
 	}
 
+	if err := h.registerPoolMetrics(); err != nil {
+		return errors.Wrap(err, "error registering connection pool metrics")
+	}
+
 	// Regular jobs
 	h.JobsQ = goqite.New(goqite.NewOpts{
 		DB:        h.DB.DB,
@@ -164,6 +198,21 @@ func scrubURL(connectionURL string) string {
 	return u.String()
 }
 
+// Close the database connection pool, and stop observing its metrics.
+func (h *Helper) Close() error {
+	if err := h.unregisterPoolMetrics(); err != nil {
+		return errors.Wrap(err, "error unregistering connection pool metrics")
+	}
+
+	if h.DB == nil {
+		return nil
+	}
+	if err := h.DB.Close(); err != nil {
+		return errors.Wrap(err, "error closing database")
+	}
+	return nil
+}
+
 // InTransaction runs callback in a transaction, and makes sure to handle rollbacks, commits etc.
 func (h *Helper) InTx(ctx context.Context, cb func(ctx context.Context, tx *Tx) error) (err error) {
 	ctx, span := h.tracer.Start(ctx, "sql.tx",
@@ -188,7 +237,7 @@ func (h *Helper) InTx(ctx context.Context, cb func(ctx context.Context, tx *Tx)
 		}
 	}()
 
-	if err := cb(ctx, &Tx{Tx: tx, queryTracerStart: h.queryTracerStart}); err != nil {
+	if err := cb(ctx, &Tx{Tx: tx, queryTracerStart: h.queryTracerStart, recordQueryDuration: h.recordQueryDuration}); err != nil {
 		err = rollback(tx, err)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "tx callback failed")
@@ -212,7 +261,13 @@ func rollback(tx *sqlx.Tx, err error) error {
 	return err
 }
 
+// Ping the database by running a trivial query in a transaction.
+// It can be used as a [maragu.dev/glue/health.Check], and fails if not connected yet.
 func (h *Helper) Ping(ctx context.Context) error {
+	if h.DB == nil {
+		return errors.New("not connected to database")
+	}
+
 	return h.InTx(ctx, func(ctx context.Context, tx *Tx) error {
 		return tx.Exec(ctx, `select 1`)
 	})
@@ -221,6 +276,7 @@ func (h *Helper) Ping(ctx context.Context) error {
 func (h *Helper) Select(ctx context.Context, dest any, query string, args ...any) error {
 	ctx, span := h.queryTracerStart(ctx, "sql.select", query)
 	defer span.End()
+	defer h.recordQueryDuration(ctx, query, time.Now())
 
 	if err := h.DB.SelectContext(ctx, dest, query, args...); err != nil {
 		span.RecordError(err)
@@ -234,6 +290,7 @@ func (h *Helper) Select(ctx context.Context, dest any, query string, args ...any
 func (h *Helper) Get(ctx context.Context, dest any, query string, args ...any) error {
 	ctx, span := h.queryTracerStart(ctx, "sql.get", query)
 	defer span.End()
+	defer h.recordQueryDuration(ctx, query, time.Now())
 
 	if err := h.DB.GetContext(ctx, dest, query, args...); err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
@@ -249,6 +306,7 @@ func (h *Helper) Get(ctx context.Context, dest any, query string, args ...any) e
 func (h *Helper) Exec(ctx context.Context, query string, args ...any) error {
 	ctx, span := h.queryTracerStart(ctx, "sql.exec", query)
 	defer span.End()
+	defer h.recordQueryDuration(ctx, query, time.Now())
 
 	if _, err := h.DB.ExecContext(ctx, query, args...); err != nil {
 		span.RecordError(err)
@@ -260,13 +318,15 @@ func (h *Helper) Exec(ctx context.Context, query string, args ...any) error {
 }
 
 type Tx struct {
-	Tx               *sqlx.Tx
-	queryTracerStart func(context.Context, string, string, ...trace.SpanStartOption) (context.Context, trace.Span)
+	Tx                  *sqlx.Tx
+	queryTracerStart    func(context.Context, string, string, ...trace.SpanStartOption) (context.Context, trace.Span)
+	recordQueryDuration func(context.Context, string, time.Time)
 }
 
 func (t *Tx) Select(ctx context.Context, dest any, query string, args ...any) error {
 	ctx, span := t.queryTracerStart(ctx, "sql.tx.select", query)
 	defer span.End()
+	defer t.recordQueryDuration(ctx, query, time.Now())
 
 	if err := t.Tx.SelectContext(ctx, dest, query, args...); err != nil {
 		span.RecordError(err)
@@ -280,6 +340,7 @@ func (t *Tx) Select(ctx context.Context, dest any, query string, args ...any) er
 func (t *Tx) Get(ctx context.Context, dest any, query string, args ...any) error {
 	ctx, span := t.queryTracerStart(ctx, "sql.tx.get", query)
 	defer span.End()
+	defer t.recordQueryDuration(ctx, query, time.Now())
 
 	if err := t.Tx.GetContext(ctx, dest, query, args...); err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
@@ -295,6 +356,7 @@ func (t *Tx) Get(ctx context.Context, dest any, query string, args ...any) error
 func (t *Tx) Exec(ctx context.Context, query string, args ...any) error {
 	ctx, span := t.queryTracerStart(ctx, "sql.tx.exec", query)
 	defer span.End()
+	defer t.recordQueryDuration(ctx, query, time.Now())
 
 	if _, err := t.Tx.ExecContext(ctx, query, args...); err != nil {
 		span.RecordError(err)
@@ -1,16 +1,64 @@
 package sql_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"maragu.dev/is"
 
+	"maragu.dev/glue/health"
 	"maragu.dev/glue/sql"
 	internaltesting "maragu.dev/glue/sql/internal/testing"
 )
 
+func TestNewHelper(t *testing.T) {
+	t.Run("registers a readiness check that passes once connected", func(t *testing.T) {
+		reg := health.NewRegistry(health.NewRegistryOptions{})
+		h := sql.NewHelper(sql.NewHelperOptions{
+			Health: reg,
+			SQLite: sql.SQLiteOptions{Path: filepath.Join(t.TempDir(), "app.db")},
+		})
+
+		report := reg.Ready(t.Context())
+		is.Equal(t, health.StatusError, report.Status)
+		is.Equal(t, "sql", report.Checks[0].Name)
+
+		err := h.Connect(t.Context())
+		is.NotError(t, err)
+		t.Cleanup(func() {
+			_ = h.Close()
+		})
+
+		report = reg.Ready(t.Context())
+		is.Equal(t, health.StatusOK, report.Status)
+	})
+}
+
 func TestHelper_Connect(t *testing.T) {
 	internaltesting.Run(t, "has a jobs queue", func(t *testing.T, h *sql.Helper) {
 		is.NotNil(t, h.JobsQ)
 	})
 }
+
+func TestHelper_MigrationStatus(t *testing.T) {
+	internaltesting.Run(t, "reports migrations as applied after migrating up", func(t *testing.T, h *sql.Helper) {
+		ms, err := h.MigrationStatus(t.Context())
+		is.NotError(t, err)
+		is.True(t, len(ms) > 0)
+		for _, m := range ms {
+			is.True(t, m.Applied)
+		}
+	})
+
+	internaltesting.Run(t, "reports migrations as not applied after migrating down", func(t *testing.T, h *sql.Helper) {
+		err := h.MigrateDown(t.Context())
+		is.NotError(t, err)
+
+		ms, err := h.MigrationStatus(t.Context())
+		is.NotError(t, err)
+		is.True(t, len(ms) > 0)
+		for _, m := range ms {
+			is.True(t, !m.Applied)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+type idempotencyKeyRow struct {
+	RequestHash string `db:"request_hash"`
+	Status      *int
+	Header      *string
+	Body        []byte
+}
+
+// StartIdempotentRequest claims the idempotency key for a request with the given hash, for the given TTL.
+// If the key is new or expired, it returns nil, and the response must be stored with [Helper.CompleteIdempotentRequest]
+// or the key released with [Helper.CancelIdempotentRequest].
+// If the key has a stored response, it returns it.
+// If the key is used for a different request, it returns [model.ErrorIdempotencyKeyRequestMismatch],
+// and if the first request hasn't completed yet, [model.ErrorIdempotencyKeyInProgress].
+func (h *Helper) StartIdempotentRequest(ctx context.Context, key, requestHash string, ttl time.Duration) (*model.IdempotentResponse, error) {
+	now := model.Now()
+
+	if err := h.Exec(ctx, `delete from idempotency_keys where key = $1 and created < $2`, key, model.Time{T: now.T.Add(-ttl)}); err != nil {
+		return nil, errors.Wrap(err, "error deleting expired idempotency key")
+	}
+
+	var inserted string
+	query := `insert into idempotency_keys (key, request_hash, created) values ($1, $2, $3) on conflict do nothing returning key`
+	err := h.Get(ctx, &inserted, query, key, requestHash, now)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "error inserting idempotency key")
+	}
+
+	var row idempotencyKeyRow
+	if err := h.Get(ctx, &row, `select request_hash, status, header, body from idempotency_keys where key = $1`, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Cancelled or expired concurrently, so the client can retry
+			return nil, model.ErrorIdempotencyKeyInProgress
+		}
+		return nil, errors.Wrap(err, "error getting idempotency key")
+	}
+
+	if row.RequestHash != requestHash {
+		return nil, model.ErrorIdempotencyKeyRequestMismatch
+	}
+
+	if row.Status == nil {
+		return nil, model.ErrorIdempotencyKeyInProgress
+	}
+
+	resp := &model.IdempotentResponse{
+		Status: *row.Status,
+		Body:   row.Body,
+	}
+	if row.Header != nil {
+		if err := json.Unmarshal([]byte(*row.Header), &resp.Header); err != nil {
+			return nil, errors.Wrap(err, "error decoding idempotent response header")
+		}
+	}
+	return resp, nil
+}
+
+// CompleteIdempotentRequest by storing the response for the idempotency key, to replay for repeated requests.
+func (h *Helper) CompleteIdempotentRequest(ctx context.Context, key string, resp model.IdempotentResponse) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return errors.Wrap(err, "error encoding idempotent response header")
+	}
+
+	query := `update idempotency_keys set status = $1, header = $2, body = $3 where key = $4`
+	if err := h.Exec(ctx, query, resp.Status, string(header), resp.Body, key); err != nil {
+		return errors.Wrap(err, "error updating idempotency key")
+	}
+	return nil
+}
+
+// CancelIdempotentRequest by deleting the idempotency key, so the request can be retried.
+func (h *Helper) CancelIdempotentRequest(ctx context.Context, key string) error {
+	if err := h.Exec(ctx, `delete from idempotency_keys where key = $1`, key); err != nil {
+		return errors.Wrap(err, "error deleting idempotency key")
+	}
+	return nil
+}
+
+// DeleteIdempotencyKeysBefore deletes idempotency keys created before the given time.
+// Call it periodically, for example with an [Elector], so expired keys don't pile up.
+func (h *Helper) DeleteIdempotencyKeysBefore(ctx context.Context, t model.Time) error {
+	if err := h.Exec(ctx, `delete from idempotency_keys where created < $1`, t); err != nil {
+		return errors.Wrap(err, "error deleting idempotency keys")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package sql_test
+
+import (
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestHelper_StartIdempotentRequest(t *testing.T) {
+	internaltesting.Run(t, "stores and replays the first response", func(t *testing.T, h *sql.Helper) {
+		resp, err := h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash1", time.Hour)
+		is.NotError(t, err)
+		is.True(t, resp == nil)
+
+		_, err = h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash1", time.Hour)
+		is.Error(t, model.ErrorIdempotencyKeyInProgress, err)
+
+		err = h.CompleteIdempotentRequest(t.Context(), "u_1:abc", model.IdempotentResponse{
+			Status: 201,
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+			Body:   []byte(`{"id":"t_1"}`),
+		})
+		is.NotError(t, err)
+
+		resp, err = h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash1", time.Hour)
+		is.NotError(t, err)
+		is.True(t, resp != nil)
+		is.Equal(t, 201, resp.Status)
+		is.Equal(t, "application/json", resp.Header["Content-Type"][0])
+		is.Equal(t, `{"id":"t_1"}`, string(resp.Body))
+
+		_, err = h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash2", time.Hour)
+		is.Error(t, model.ErrorIdempotencyKeyRequestMismatch, err)
+	})
+
+	internaltesting.Run(t, "can retry after cancelling or expiry", func(t *testing.T, h *sql.Helper) {
+		_, err := h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash1", time.Hour)
+		is.NotError(t, err)
+
+		err = h.CancelIdempotentRequest(t.Context(), "u_1:abc")
+		is.NotError(t, err)
+
+		resp, err := h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash1", time.Hour)
+		is.NotError(t, err)
+		is.True(t, resp == nil)
+
+		time.Sleep(2 * time.Millisecond)
+
+		resp, err = h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash2", time.Millisecond)
+		is.NotError(t, err)
+		is.True(t, resp == nil)
+
+		err = h.DeleteIdempotencyKeysBefore(t.Context(), model.Time{T: time.Now().Add(time.Minute)})
+		is.NotError(t, err)
+
+		resp, err = h.StartIdempotentRequest(t.Context(), "u_1:abc", "hash1", time.Hour)
+		is.NotError(t, err)
+		is.True(t, resp == nil)
+	})
+}
@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+// impersonationRow is a [model.Impersonation] with column names.
+type impersonationRow struct {
+	ID          model.ImpersonationID
+	AdminUserID model.UserID `db:"admin_user_id"`
+	UserID      model.UserID `db:"user_id"`
+	Started     model.Time
+	Stopped     *model.Time
+}
+
+// StartImpersonation records that the admin user started impersonating the user, and returns the ID of the record.
+func (h *Helper) StartImpersonation(ctx context.Context, adminUserID, userID model.UserID) (model.ImpersonationID, error) {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	id := model.ImpersonationID("imp_" + hex.EncodeToString(b))
+
+	query := `insert into impersonations (id, admin_user_id, user_id, started) values ($1, $2, $3, $4)`
+	if err := h.Exec(ctx, query, id, adminUserID, userID, model.Now()); err != nil {
+		return "", errors.Wrap(err, "error inserting impersonation")
+	}
+	return id, nil
+}
+
+// StopImpersonation records that the impersonation with the given ID stopped.
+// Stopping an impersonation that has already stopped does nothing.
+func (h *Helper) StopImpersonation(ctx context.Context, id model.ImpersonationID) error {
+	query := `update impersonations set stopped = $1 where id = $2 and stopped is null`
+	if err := h.Exec(ctx, query, model.Now(), id); err != nil {
+		return errors.Wrap(err, "error updating impersonation")
+	}
+	return nil
+}
+
+// GetImpersonations of the given user by admins, newest first.
+func (h *Helper) GetImpersonations(ctx context.Context, userID model.UserID) ([]model.Impersonation, error) {
+	var rows []impersonationRow
+	query := `
+		select id, admin_user_id, user_id, started, stopped
+		from impersonations where user_id = $1 order by started desc, id`
+	if err := h.Select(ctx, &rows, query, userID); err != nil {
+		return nil, errors.Wrap(err, "error getting impersonations")
+	}
+
+	impersonations := []model.Impersonation{}
+	for _, r := range rows {
+		impersonations = append(impersonations, model.Impersonation(r))
+	}
+	return impersonations, nil
+}
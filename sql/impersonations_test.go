@@ -0,0 +1,36 @@
+package sql_test
+
+import (
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestHelper_Impersonations(t *testing.T) {
+	internaltesting.Run(t, "starts, stops, and gets impersonations", func(t *testing.T, h *sql.Helper) {
+		id, err := h.StartImpersonation(t.Context(), "u_admin", "u_1")
+		is.NotError(t, err)
+
+		impersonations, err := h.GetImpersonations(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.Equal(t, 1, len(impersonations))
+		is.Equal(t, id, impersonations[0].ID)
+		is.Equal(t, model.UserID("u_admin"), impersonations[0].AdminUserID)
+		is.True(t, impersonations[0].Stopped == nil)
+
+		err = h.StopImpersonation(t.Context(), id)
+		is.NotError(t, err)
+
+		impersonations, err = h.GetImpersonations(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.True(t, impersonations[0].Stopped != nil)
+
+		impersonations, err = h.GetImpersonations(t.Context(), "u_admin")
+		is.NotError(t, err)
+		is.Equal(t, 0, len(impersonations))
+	})
+}
@@ -0,0 +1,310 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+// Elector campaigns for leadership of a named election among all processes using the same database,
+// so only one of them does singleton work, such as periodic cleanup.
+// On PostgreSQL, leadership is a session-level advisory lock held on a dedicated connection.
+// On SQLite, leadership is a lease in the leader_leases table, renewed periodically.
+// Create it with [Helper.NewElector], and run it with [Elector.Run].
+type Elector struct {
+	attributes []attribute.KeyValue
+	backend    leaderBackend
+	callbacks  []func(ctx context.Context)
+	cancel     context.CancelFunc
+	id         string
+	interval   time.Duration
+	isLeader   atomic.Bool
+	lock       sync.Mutex
+	log        *slog.Logger
+	name       string
+	tracer     trace.Tracer
+	wg         sync.WaitGroup
+}
+
+type NewElectorOptions struct {
+	// ID of this candidate. Defaults to the hostname and a random suffix.
+	ID string
+
+	// Interval between attempts to become leader, and between checks that leadership is still held.
+	// Defaults to 5 seconds.
+	Interval time.Duration
+
+	// LeaseDuration is how long a lease is valid for without renewal, on SQLite only.
+	// Must be longer than Interval. Defaults to three times Interval.
+	LeaseDuration time.Duration
+
+	// Name of the election. Candidates with the same name compete for the same leadership.
+	Name string
+}
+
+// NewElector for the election with the given name. [Helper.Connect] must have been called first.
+func (h *Helper) NewElector(opts NewElectorOptions) *Elector {
+	if opts.Name == "" {
+		panic("election name must not be empty")
+	}
+
+	if opts.ID == "" {
+		hostname, _ := os.Hostname()
+		opts.ID = hostname + "-" + rand.Text()[:8]
+	}
+
+	if opts.Interval == 0 {
+		opts.Interval = 5 * time.Second
+	}
+
+	if opts.LeaseDuration == 0 {
+		opts.LeaseDuration = 3 * opts.Interval
+	}
+
+	var backend leaderBackend
+	if h.path != "" {
+		backend = &leaseBackend{h: h, name: opts.Name, id: opts.ID, leaseDuration: opts.LeaseDuration}
+	} else {
+		backend = &advisoryLockBackend{h: h, key: advisoryLockKey(opts.Name)}
+	}
+
+	return &Elector{
+		attributes: append([]attribute.KeyValue{
+			attribute.String("leader.election", opts.Name),
+			attribute.String("leader.id", opts.ID),
+		}, h.attributes...),
+		backend:  backend,
+		id:       opts.ID,
+		interval: opts.Interval,
+		log:      h.log,
+		name:     opts.Name,
+		tracer:   otel.Tracer("maragu.dev/glue/sql"),
+	}
+}
+
+// OnElected registers a callback that is called in its own goroutine every time this candidate becomes leader.
+// The context given to the callback is cancelled when leadership is lost or [Elector.Run] returns,
+// and leadership isn't given up voluntarily until all callbacks have returned.
+// Register callbacks before calling [Elector.Run].
+func (e *Elector) OnElected(cb func(ctx context.Context)) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.callbacks = append(e.callbacks, cb)
+}
+
+// IsLeader returns whether this candidate currently holds leadership.
+func (e *Elector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+// Run the election campaign, blocking until ctx is done, at which point leadership is given up if held.
+// It can be used as the Run function of a [maragu.dev/glue/app.Component].
+func (e *Elector) Run(ctx context.Context) error {
+	e.log.InfoContext(ctx, "Starting leader election", "election", e.name, "id", e.id)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if e.IsLeader() {
+			if err := e.backend.renew(ctx); err != nil && ctx.Err() == nil {
+				e.demote(ctx, err)
+			}
+		} else {
+			acquired, err := e.backend.tryAcquire(ctx)
+			if err != nil && ctx.Err() == nil {
+				e.log.InfoContext(ctx, "Error trying to become leader", "election", e.name, "error", err)
+			}
+			if acquired {
+				e.elect(ctx)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if e.IsLeader() {
+				e.demote(ctx, nil)
+				if err := e.backend.release(context.WithoutCancel(ctx)); err != nil {
+					e.log.InfoContext(ctx, "Error giving up leadership", "election", e.name, "error", err)
+				}
+			}
+			e.log.InfoContext(ctx, "Stopped leader election", "election", e.name, "id", e.id)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// elect this candidate as leader, and start the callbacks.
+func (e *Elector) elect(ctx context.Context) {
+	_, span := e.tracer.Start(ctx, "sql.leader.elected", trace.WithAttributes(e.attributes...))
+	defer span.End()
+
+	e.log.InfoContext(ctx, "Elected leader", "election", e.name, "id", e.id)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	// The callbacks shouldn't stop just because ctx is done, but when leadership is given up
+	callbackCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	e.cancel = cancel
+	e.isLeader.Store(true)
+
+	for _, cb := range e.callbacks {
+		e.wg.Go(func() {
+			cb(callbackCtx)
+		})
+	}
+}
+
+// demote this candidate, waiting for the callbacks to return. err is the reason leadership was lost, if any.
+func (e *Elector) demote(ctx context.Context, err error) {
+	_, span := e.tracer.Start(ctx, "sql.leader.demoted", trace.WithAttributes(e.attributes...))
+	defer span.End()
+
+	if err != nil {
+		span.RecordError(err)
+		e.log.InfoContext(ctx, "Lost leadership", "election", e.name, "id", e.id, "error", err)
+	} else {
+		e.log.InfoContext(ctx, "Giving up leadership", "election", e.name, "id", e.id)
+	}
+
+	e.isLeader.Store(false)
+	e.cancel()
+	e.wg.Wait()
+}
+
+// leaderBackend is the database-specific part of an [Elector].
+type leaderBackend interface {
+	// tryAcquire leadership without blocking, returning whether it was acquired.
+	tryAcquire(ctx context.Context) (bool, error)
+	// renew leadership, returning an error if it's no longer held.
+	renew(ctx context.Context) error
+	// release leadership.
+	release(ctx context.Context) error
+}
+
+// advisoryLockBackend holds a PostgreSQL session-level advisory lock on a dedicated connection.
+// The lock is released by the database if the connection is lost.
+type advisoryLockBackend struct {
+	conn *sql.Conn
+	h    *Helper
+	key  int64
+}
+
+// advisoryLockKey for the election name.
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("maragu.dev/glue/sql.leader." + name))
+	return int64(h.Sum64())
+}
+
+func (b *advisoryLockBackend) tryAcquire(ctx context.Context) (bool, error) {
+	conn, err := b.h.DB.Conn(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "error getting connection")
+	}
+
+	ctx, span := b.h.queryTracerStart(ctx, "sql.get", `select pg_try_advisory_lock($1)`)
+	defer span.End()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `select pg_try_advisory_lock($1)`, b.key).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return false, errors.Wrap(err, "error trying advisory lock")
+	}
+
+	if !acquired {
+		if err := conn.Close(); err != nil {
+			return false, errors.Wrap(err, "error closing connection")
+		}
+		return false, nil
+	}
+
+	b.conn = conn
+	return true, nil
+}
+
+func (b *advisoryLockBackend) renew(ctx context.Context) error {
+	if err := b.conn.PingContext(ctx); err != nil {
+		_ = b.conn.Close()
+		return errors.Wrap(err, "error pinging advisory lock connection")
+	}
+	return nil
+}
+
+func (b *advisoryLockBackend) release(ctx context.Context) error {
+	defer func() {
+		_ = b.conn.Close()
+	}()
+
+	ctx, span := b.h.queryTracerStart(ctx, "sql.exec", `select pg_advisory_unlock($1)`)
+	defer span.End()
+
+	if _, err := b.conn.ExecContext(ctx, `select pg_advisory_unlock($1)`, b.key); err != nil {
+		return errors.Wrap(err, "error releasing advisory lock")
+	}
+	return nil
+}
+
+// leaseBackend holds a lease in the leader_leases table, which expires if not renewed.
+type leaseBackend struct {
+	h             *Helper
+	id            string
+	leaseDuration time.Duration
+	name          string
+}
+
+// tryAcquire the lease if it's free, expired, or already held by us, which also renews it.
+func (b *leaseBackend) tryAcquire(ctx context.Context) (bool, error) {
+	var holder string
+	err := b.h.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+		now := model.Now()
+		expires := model.Time{T: now.T.Add(b.leaseDuration)}
+
+		query := `
+			insert into leader_leases (name, holder, expires) values ($1, $2, $3)
+			on conflict (name) do update set holder = excluded.holder, expires = excluded.expires
+			where leader_leases.holder = excluded.holder or leader_leases.expires < $4`
+		if err := tx.Exec(ctx, query, b.name, b.id, expires, now); err != nil {
+			return err
+		}
+
+		return tx.Get(ctx, &holder, `select holder from leader_leases where name = $1`, b.name)
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "error acquiring lease")
+	}
+	return holder == b.id, nil
+}
+
+func (b *leaseBackend) renew(ctx context.Context) error {
+	acquired, err := b.tryAcquire(ctx)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return errors.New("lease taken by another candidate")
+	}
+	return nil
+}
+
+func (b *leaseBackend) release(ctx context.Context) error {
+	if err := b.h.Exec(ctx, `delete from leader_leases where name = $1 and holder = $2`, b.name, b.id); err != nil {
+		return errors.Wrap(err, "error releasing lease")
+	}
+	return nil
+}
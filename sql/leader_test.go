@@ -0,0 +1,91 @@
+package sql_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/oteltest"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestElector(t *testing.T) {
+	internaltesting.Run(t, "elects exactly one leader, and another when the leader stops", func(t *testing.T, h *sql.Helper) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		var elected atomic.Int32
+		newElector := func(id string) *sql.Elector {
+			e := h.NewElector(sql.NewElectorOptions{ID: id, Interval: 10 * time.Millisecond, Name: "test"})
+			e.OnElected(func(ctx context.Context) {
+				elected.Add(1)
+				<-ctx.Done()
+				elected.Add(-1)
+			})
+			return e
+		}
+
+		e1 := newElector("e1")
+		e2 := newElector("e2")
+
+		ctx1, cancel1 := context.WithCancel(t.Context())
+		done1 := run(ctx1, e1)
+		waitFor(t, e1.IsLeader)
+
+		ctx2, cancel2 := context.WithCancel(t.Context())
+		done2 := run(ctx2, e2)
+
+		// Give e2 a chance to (wrongly) become leader
+		time.Sleep(50 * time.Millisecond)
+		is.True(t, e1.IsLeader())
+		is.True(t, !e2.IsLeader())
+		is.Equal(t, int32(1), elected.Load())
+
+		cancel1()
+		<-done1
+		is.True(t, !e1.IsLeader())
+
+		waitFor(t, e2.IsLeader)
+		waitFor(t, func() bool { return elected.Load() == 1 })
+
+		cancel2()
+		<-done2
+		is.Equal(t, int32(0), elected.Load())
+
+		var electedSpans, demotedSpans int
+		for _, s := range sr.Ended() {
+			switch s.Name() {
+			case "sql.leader.elected":
+				electedSpans++
+			case "sql.leader.demoted":
+				demotedSpans++
+			}
+		}
+		is.Equal(t, 2, electedSpans)
+		is.Equal(t, 2, demotedSpans)
+	})
+}
+
+func run(ctx context.Context, e *sql.Elector) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		_ = e.Run(ctx)
+		close(done)
+	}()
+	return done
+}
+
+func waitFor(t *testing.T, f func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !f() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
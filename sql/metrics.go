@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// recordQueryDuration since start for the given query.
+// The db.operation.name attribute is the SQL keyword of the query, if it's a known one, to keep cardinality low.
+func (h *Helper) recordQueryDuration(ctx context.Context, query string, start time.Time) {
+	attrs := h.attributes
+	if operation := getOperationName(query); operation != "" {
+		attrs = append(attrs[:len(attrs):len(attrs)], semconv.DBOperationName(operation))
+	}
+	h.queryDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+// getOperationName from the first keyword of the query, like SELECT or INSERT, or the empty string if it's unknown.
+func getOperationName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	switch operation := strings.ToUpper(fields[0]); operation {
+	case "SELECT", "INSERT", "UPDATE", "DELETE":
+		return operation
+	default:
+		return ""
+	}
+}
+
+// registerPoolMetrics for the connection pool, observed from [sql.DB.Stats] when metrics are collected.
+// A registration from a previous connect is replaced, so the pool isn't observed more than once.
+func (h *Helper) registerPoolMetrics() error {
+	if err := h.unregisterPoolMetrics(); err != nil {
+		return err
+	}
+
+	count, err := h.meter.Int64ObservableUpDownCounter("db.client.connection.count",
+		metric.WithDescription("The number of connections that are currently in the state described by the state attribute."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	maxCount, err := h.meter.Int64ObservableUpDownCounter("db.client.connection.max",
+		metric.WithDescription("The maximum number of open connections allowed."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	waitTime, err := h.meter.Float64ObservableCounter("db.client.connection.wait_time",
+		metric.WithDescription("The total time it took to obtain open connections from the pool."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+
+	h.poolMetrics, err = h.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		stats := h.DB.Stats()
+		attrs := metric.WithAttributes(h.attributes...)
+
+		o.ObserveInt64(count, int64(stats.Idle), attrs, metric.WithAttributes(attribute.String("db.client.connection.state", "idle")))
+		o.ObserveInt64(count, int64(stats.InUse), attrs, metric.WithAttributes(attribute.String("db.client.connection.state", "used")))
+		o.ObserveInt64(maxCount, int64(stats.MaxOpenConnections), attrs)
+		o.ObserveFloat64(waitTime, stats.WaitDuration.Seconds(), attrs)
+		return nil
+	}, count, maxCount, waitTime)
+
+	return err
+}
+
+// unregisterPoolMetrics registered by [Helper.registerPoolMetrics], if any.
+func (h *Helper) unregisterPoolMetrics() error {
+	if h.poolMetrics == nil {
+		return nil
+	}
+	if err := h.poolMetrics.Unregister(); err != nil {
+		return err
+	}
+	h.poolMetrics = nil
+	return nil
+}
@@ -0,0 +1,61 @@
+package sql_test
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"maragu.dev/is"
+
+	"maragu.dev/glue/oteltest"
+	"maragu.dev/glue/sqlitetest"
+)
+
+func TestHelper_metrics(t *testing.T) {
+	t.Run("records query durations and connection pool stats", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+		h := sqlitetest.NewHelper(t)
+
+		var n int
+		err := h.Get(t.Context(), &n, `select 1`)
+		is.NotError(t, err)
+
+		m := oteltest.GetMetric(t, r, "db.client.operation.duration")
+		hist, ok := m.Data.(metricdata.Histogram[float64])
+		is.True(t, ok)
+
+		var found bool
+		for _, dp := range hist.DataPoints {
+			if v, ok := dp.Attributes.Value("db.operation.name"); ok && v.AsString() == "SELECT" {
+				found = true
+			}
+		}
+		is.True(t, found)
+
+		m = oteltest.GetMetric(t, r, "db.client.connection.count")
+		_, ok = m.Data.(metricdata.Sum[int64])
+		is.True(t, ok)
+	})
+	t.Run("observes the connection pool once after reconnecting", func(t *testing.T) {
+		r := oteltest.NewMetricReader(t)
+		h := sqlitetest.NewHelper(t)
+
+		err := h.Connect(t.Context())
+		is.NotError(t, err)
+
+		var n int
+		err = h.Get(t.Context(), &n, `select 1`)
+		is.NotError(t, err)
+
+		m := oteltest.GetMetric(t, r, "db.client.connection.count")
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		is.True(t, ok)
+
+		var idle int64
+		for _, dp := range sum.DataPoints {
+			if v, ok := dp.Attributes.Value("db.client.connection.state"); ok && v.AsString() == "idle" {
+				idle += dp.Value
+			}
+		}
+		is.Equal(t, int64(h.DB.Stats().Idle), idle)
+	})
+}
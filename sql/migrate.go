@@ -7,9 +7,11 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sync"
 	"testing/fstest"
 
+	"maragu.dev/errors"
 	"maragu.dev/migrate"
 )
 
@@ -26,6 +28,61 @@ func (h *Helper) MigrateDown(ctx context.Context) error {
 	return migrate.Down(ctx, h.DB.DB, h.getMigrations())
 }
 
+// Migration with its version and whether it has been applied, as returned by [Helper.MigrationStatus].
+type Migration struct {
+	Version string
+	Applied bool
+}
+
+var upMigrationMatcher = regexp.MustCompile(`^([\w-]+)\.up\.sql$`)
+
+// MigrationStatus returns all known up migrations in order, and whether each has been applied.
+// It doesn't change the database, so migrations are reported as not applied if no migrations have ever been run.
+func (h *Helper) MigrationStatus(ctx context.Context) ([]Migration, error) {
+	currentVersion, err := h.getCurrentMigrationVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := fs.Glob(h.getMigrations(), "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []Migration
+	for _, name := range names {
+		if !upMigrationMatcher.MatchString(name) {
+			continue
+		}
+		version := upMigrationMatcher.ReplaceAllString(name, "$1")
+		ms = append(ms, Migration{Version: version, Applied: version <= currentVersion})
+	}
+
+	return ms, nil
+}
+
+// getCurrentMigrationVersion from the migrations table used by [migrate], or the empty string if it doesn't exist.
+func (h *Helper) getCurrentMigrationVersion(ctx context.Context) (string, error) {
+	query := `select count(*) from information_schema.tables where table_schema = current_schema() and table_name = 'migrations'`
+	if h.path != "" {
+		query = `select count(*) from sqlite_master where type = 'table' and name = 'migrations'`
+	}
+
+	var count int
+	if err := h.Get(ctx, &count, query); err != nil {
+		return "", errors.Wrap(err, "error checking for migrations table")
+	}
+	if count == 0 {
+		return "", nil
+	}
+
+	var version string
+	if err := h.Get(ctx, &version, `select version from migrations`); err != nil {
+		return "", errors.Wrap(err, "error getting current migration version")
+	}
+	return version, nil
+}
+
 // getMigrations both embedded here in this module, as well as in the client module.
 func (h *Helper) getMigrations() fs.FS {
 	migrationsOnce.Do(func() {
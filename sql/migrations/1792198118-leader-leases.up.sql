@@ -0,0 +1,5 @@
+create table leader_leases (
+  name text primary key,
+  holder text not null,
+  expires text not null
+);
@@ -0,0 +1,9 @@
+create table tokens (
+  hash text primary key,
+  kind text not null,
+  user_id text not null,
+  expires text not null,
+  created text not null
+);
+
+create index tokens_expires_idx on tokens (expires);
@@ -0,0 +1 @@
+alter table tokens drop column data;
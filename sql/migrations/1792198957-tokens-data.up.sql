@@ -0,0 +1 @@
+alter table tokens add column data text not null default '';
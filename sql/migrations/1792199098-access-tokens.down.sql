@@ -0,0 +1 @@
+drop table access_tokens;
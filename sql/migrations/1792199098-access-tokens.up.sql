@@ -0,0 +1,12 @@
+create table access_tokens (
+  id text primary key,
+  user_id text not null,
+  name text not null,
+  hash text not null unique,
+  scopes text not null,
+  created text not null,
+  last_used text,
+  expires text
+);
+
+create index access_tokens_user_id_idx on access_tokens (user_id);
@@ -0,0 +1,6 @@
+create table user_roles (
+  user_id text not null,
+  role text not null,
+  created text not null,
+  primary key (user_id, role)
+);
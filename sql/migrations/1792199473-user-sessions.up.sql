@@ -0,0 +1,12 @@
+create table user_sessions (
+  id text primary key,
+  user_id text not null,
+  ip text not null,
+  browser text not null,
+  os text not null,
+  created text not null,
+  last_seen text not null
+);
+
+create index user_sessions_user_id_idx on user_sessions (user_id);
+create index user_sessions_last_seen_idx on user_sessions (last_seen);
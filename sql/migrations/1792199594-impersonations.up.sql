@@ -0,0 +1,10 @@
+create table impersonations (
+  id text primary key,
+  admin_user_id text not null,
+  user_id text not null,
+  started text not null,
+  stopped text
+);
+
+create index impersonations_admin_user_id_idx on impersonations (admin_user_id);
+create index impersonations_user_id_idx on impersonations (user_id);
@@ -0,0 +1,8 @@
+create table rate_limits (
+  key text primary key,
+  tokens double precision not null,
+  updated text not null,
+  version integer not null
+);
+
+create index rate_limits_updated_idx on rate_limits (updated);
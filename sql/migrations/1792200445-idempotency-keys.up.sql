@@ -0,0 +1,11 @@
+create table idempotency_keys (
+  key text primary key,
+  request_hash text not null,
+  created text not null,
+  status integer,
+  header text,
+  -- bytea is binary in Postgres. SQLite gives it numeric affinity, which only converts text values, so blobs are stored unchanged.
+  body bytea
+);
+
+create index idempotency_keys_created_idx on idempotency_keys (created);
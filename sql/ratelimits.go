@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+// rateLimitAttempts is how many times taking a token is attempted when other processes change the same bucket concurrently.
+const rateLimitAttempts = 5
+
+type rateLimitRow struct {
+	Tokens  float64
+	Updated model.Time
+	Version int
+}
+
+// TakeRateLimitToken from the token bucket with the given key, shared by all processes using the database.
+// Returns whether a token was taken, and if not, how long until the next token is available.
+// Buckets are updated with optimistic concurrency instead of a transaction, so concurrent requests don't fail on serialization errors.
+func (h *Helper) TakeRateLimitToken(ctx context.Context, key string, limit model.RateLimit) (bool, time.Duration, error) {
+	for range rateLimitAttempts {
+		now := model.Now()
+
+		var row rateLimitRow
+		err := h.Get(ctx, &row, `select tokens, updated, version from rate_limits where key = $1`, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			tokens, ok, wait := limit.Take(float64(limit.Burst), now.T, now.T)
+
+			var inserted string
+			query := `insert into rate_limits (key, tokens, updated, version) values ($1, $2, $3, 1) on conflict do nothing returning key`
+			if err := h.Get(ctx, &inserted, query, key, tokens, now); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				return false, 0, errors.Wrap(err, "error inserting rate limit")
+			}
+			return ok, wait, nil
+		}
+		if err != nil {
+			return false, 0, errors.Wrap(err, "error getting rate limit")
+		}
+
+		tokens, ok, wait := limit.Take(row.Tokens, row.Updated.T, now.T)
+		if !ok {
+			// Nothing was taken, so the bucket doesn't need updating
+			return false, wait, nil
+		}
+
+		var version int
+		query := `update rate_limits set tokens = $1, updated = $2, version = version + 1 where key = $3 and version = $4 returning version`
+		if err := h.Get(ctx, &version, query, tokens, now, key, row.Version); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return false, 0, errors.Wrap(err, "error updating rate limit")
+		}
+		return true, 0, nil
+	}
+
+	return false, 0, errors.New("error taking rate limit token: bucket changed concurrently too many times")
+}
+
+// DeleteRateLimitsBefore deletes token buckets not updated since the given time.
+// Buckets that have refilled completely can be deleted without changing any limits, since new buckets start full.
+// Call it periodically, for example with an [Elector], so buckets for one-off keys don't pile up.
+func (h *Helper) DeleteRateLimitsBefore(ctx context.Context, t model.Time) error {
+	if err := h.Exec(ctx, `delete from rate_limits where updated < $1`, t); err != nil {
+		return errors.Wrap(err, "error deleting rate limits")
+	}
+	return nil
+}
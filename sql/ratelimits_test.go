@@ -0,0 +1,48 @@
+package sql_test
+
+import (
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestHelper_TakeRateLimitToken(t *testing.T) {
+	internaltesting.Run(t, "takes tokens until the bucket is empty", func(t *testing.T, h *sql.Helper) {
+		limit := model.RateLimit{Burst: 2, Every: time.Hour}
+
+		for range 2 {
+			ok, _, err := h.TakeRateLimitToken(t.Context(), "ip:127.0.0.1", limit)
+			is.NotError(t, err)
+			is.True(t, ok)
+		}
+
+		ok, wait, err := h.TakeRateLimitToken(t.Context(), "ip:127.0.0.1", limit)
+		is.NotError(t, err)
+		is.True(t, !ok)
+		is.True(t, wait > 59*time.Minute)
+
+		ok, _, err = h.TakeRateLimitToken(t.Context(), "ip:127.0.0.2", limit)
+		is.NotError(t, err)
+		is.True(t, ok)
+	})
+
+	internaltesting.Run(t, "deletes buckets not updated since a time", func(t *testing.T, h *sql.Helper) {
+		limit := model.RateLimit{Burst: 1, Every: time.Hour}
+
+		ok, _, err := h.TakeRateLimitToken(t.Context(), "user:u_1", limit)
+		is.NotError(t, err)
+		is.True(t, ok)
+
+		err = h.DeleteRateLimitsBefore(t.Context(), model.Time{T: time.Now().Add(time.Minute)})
+		is.NotError(t, err)
+
+		ok, _, err = h.TakeRateLimitToken(t.Context(), "user:u_1", limit)
+		is.NotError(t, err)
+		is.True(t, ok)
+	})
+}
@@ -0,0 +1,35 @@
+package sql
+
+import (
+	"context"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+// GrantRole to the user. Granting a role the user already has does nothing.
+func (h *Helper) GrantRole(ctx context.Context, userID model.UserID, role model.Role) error {
+	query := `insert into user_roles (user_id, role, created) values ($1, $2, $3) on conflict do nothing`
+	if err := h.Exec(ctx, query, userID, role, model.Now()); err != nil {
+		return errors.Wrap(err, "error granting role")
+	}
+	return nil
+}
+
+// RevokeRole from the user. Revoking a role the user doesn't have does nothing.
+func (h *Helper) RevokeRole(ctx context.Context, userID model.UserID, role model.Role) error {
+	if err := h.Exec(ctx, `delete from user_roles where user_id = $1 and role = $2`, userID, role); err != nil {
+		return errors.Wrap(err, "error revoking role")
+	}
+	return nil
+}
+
+// GetRoles of the user, sorted.
+func (h *Helper) GetRoles(ctx context.Context, userID model.UserID) ([]model.Role, error) {
+	roles := []model.Role{}
+	if err := h.Select(ctx, &roles, `select role from user_roles where user_id = $1 order by role`, userID); err != nil {
+		return nil, errors.Wrap(err, "error getting roles")
+	}
+	return roles, nil
+}
@@ -0,0 +1,35 @@
+package sql_test
+
+import (
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestHelper_Roles(t *testing.T) {
+	internaltesting.Run(t, "grants, gets, and revokes roles", func(t *testing.T, h *sql.Helper) {
+		roles, err := h.GetRoles(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.Equal(t, 0, len(roles))
+
+		is.NotError(t, h.GrantRole(t.Context(), "u_1", "editor"))
+		is.NotError(t, h.GrantRole(t.Context(), "u_1", "admin"))
+		is.NotError(t, h.GrantRole(t.Context(), "u_1", "admin"))
+		is.NotError(t, h.GrantRole(t.Context(), "u_2", "viewer"))
+
+		roles, err = h.GetRoles(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.EqualSlice(t, []model.Role{"admin", "editor"}, roles)
+
+		is.NotError(t, h.RevokeRole(t.Context(), "u_1", "admin"))
+		is.NotError(t, h.RevokeRole(t.Context(), "u_1", "admin"))
+
+		roles, err = h.GetRoles(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.EqualSlice(t, []model.Role{"editor"}, roles)
+	})
+}
@@ -0,0 +1,148 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+// sessionLastSeenInterval is how often the last seen time of a session is updated at most,
+// so requests in the same session don't write on every request.
+const sessionLastSeenInterval = time.Minute
+
+// sessionRow is a [model.Session] with column names.
+type sessionRow struct {
+	ID       model.SessionID
+	UserID   model.UserID `db:"user_id"`
+	IP       string
+	Browser  string
+	OS       string
+	Created  model.Time
+	LastSeen model.Time `db:"last_seen"`
+}
+
+// CreateSession for the given user, with the IP address and parsed user agent of the request that created it.
+func (h *Helper) CreateSession(ctx context.Context, userID model.UserID, ip, browser, os string) (model.Session, error) {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	now := model.Now()
+	s := model.Session{
+		ID:       model.SessionID("s_" + hex.EncodeToString(id)),
+		UserID:   userID,
+		IP:       ip,
+		Browser:  browser,
+		OS:       os,
+		Created:  now,
+		LastSeen: now,
+	}
+
+	query := `
+		insert into user_sessions (id, user_id, ip, browser, os, created, last_seen)
+		values ($1, $2, $3, $4, $5, $6, $7)`
+	if err := h.Exec(ctx, query, s.ID, s.UserID, s.IP, s.Browser, s.OS, s.Created, s.LastSeen); err != nil {
+		return model.Session{}, errors.Wrap(err, "error inserting session")
+	}
+
+	return s, nil
+}
+
+// TouchSession returns the session with the given ID, and updates its last seen time.
+// Returns [model.ErrorSessionNotFound] if the session doesn't exist or has been revoked.
+func (h *Helper) TouchSession(ctx context.Context, id model.SessionID) (model.Session, error) {
+	var row sessionRow
+	query := `select id, user_id, ip, browser, os, created, last_seen from user_sessions where id = $1`
+	if err := h.Get(ctx, &row, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Session{}, model.ErrorSessionNotFound
+		}
+		return model.Session{}, errors.Wrap(err, "error getting session")
+	}
+	s := model.Session(row)
+
+	now := model.Now()
+	if now.T.Sub(s.LastSeen.T) >= sessionLastSeenInterval {
+		if err := h.Exec(ctx, `update user_sessions set last_seen = $1 where id = $2`, now, s.ID); err != nil {
+			return model.Session{}, errors.Wrap(err, "error updating session last seen time")
+		}
+		s.LastSeen = now
+	}
+
+	return s, nil
+}
+
+// GetSessions of the given user, most recently seen first.
+func (h *Helper) GetSessions(ctx context.Context, userID model.UserID) ([]model.Session, error) {
+	var rows []sessionRow
+	query := `
+		select id, user_id, ip, browser, os, created, last_seen
+		from user_sessions where user_id = $1 order by last_seen desc, id`
+	if err := h.Select(ctx, &rows, query, userID); err != nil {
+		return nil, errors.Wrap(err, "error getting sessions")
+	}
+
+	sessions := []model.Session{}
+	for _, r := range rows {
+		sessions = append(sessions, model.Session(r))
+	}
+	return sessions, nil
+}
+
+// RevokeSession with the given ID belonging to the given user, by deleting it.
+// Returns [model.ErrorSessionNotFound] if the user has no such session.
+func (h *Helper) RevokeSession(ctx context.Context, userID model.UserID, id model.SessionID) error {
+	var deletedID model.SessionID
+	query := `delete from user_sessions where id = $1 and user_id = $2 returning id`
+	if err := h.Get(ctx, &deletedID, query, id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrorSessionNotFound
+		}
+		return errors.Wrap(err, "error deleting session")
+	}
+	return nil
+}
+
+// RevokeSessions of the given user, logging them out everywhere.
+// To deactivate a user, use [Helper.DeactivateUser], which also revokes the user's sessions.
+func (h *Helper) RevokeSessions(ctx context.Context, userID model.UserID) error {
+	if err := h.Exec(ctx, `delete from user_sessions where user_id = $1`, userID); err != nil {
+		return errors.Wrap(err, "error deleting sessions")
+	}
+	return nil
+}
+
+// DeleteSessionsBefore deletes sessions not seen since the given time.
+// Sessions expire in the session manager without being revoked, so call it periodically with a time
+// at least the session lifetime ago, for example with an [Elector], so expired sessions don't pile up.
+func (h *Helper) DeleteSessionsBefore(ctx context.Context, t model.Time) error {
+	if err := h.Exec(ctx, `delete from user_sessions where last_seen < $1`, t); err != nil {
+		return errors.Wrap(err, "error deleting sessions")
+	}
+	return nil
+}
+
+// UserDeactivator is implemented by the app to mark a user inactive, in the transaction of [Helper.DeactivateUser].
+type UserDeactivator interface {
+	DeactivateUser(ctx context.Context, tx *Tx, userID model.UserID) error
+}
+
+// DeactivateUser with the given [UserDeactivator] and revoke all of the user's sessions in the same transaction,
+// so the user is logged out everywhere immediately, not just on the next request.
+func (h *Helper) DeactivateUser(ctx context.Context, userID model.UserID, u UserDeactivator) error {
+	return h.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+		if err := u.DeactivateUser(ctx, tx, userID); err != nil {
+			return errors.Wrap(err, "error deactivating user")
+		}
+
+		if err := tx.Exec(ctx, `delete from user_sessions where user_id = $1`, userID); err != nil {
+			return errors.Wrap(err, "error deleting sessions")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestHelper_Sessions(t *testing.T) {
+	internaltesting.Run(t, "creates, touches, lists, and revokes sessions", func(t *testing.T, h *sql.Helper) {
+		s, err := h.CreateSession(t.Context(), "u_1", "127.0.0.1", "Firefox 130.0", "macOS 14.0")
+		is.NotError(t, err)
+		is.True(t, strings.HasPrefix(s.ID.String(), "s_"))
+
+		touched, err := h.TouchSession(t.Context(), s.ID)
+		is.NotError(t, err)
+		is.Equal(t, s.ID, touched.ID)
+		is.Equal(t, model.UserID("u_1"), touched.UserID)
+		is.Equal(t, "127.0.0.1", touched.IP)
+		is.Equal(t, "Firefox 130.0", touched.Browser)
+		is.Equal(t, "macOS 14.0", touched.OS)
+
+		sessions, err := h.GetSessions(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.Equal(t, 1, len(sessions))
+		is.Equal(t, s.ID, sessions[0].ID)
+
+		err = h.RevokeSession(t.Context(), "u_1", s.ID)
+		is.NotError(t, err)
+
+		_, err = h.TouchSession(t.Context(), s.ID)
+		is.Error(t, model.ErrorSessionNotFound, err)
+	})
+
+	internaltesting.Run(t, "does not revoke another user's session", func(t *testing.T, h *sql.Helper) {
+		s, err := h.CreateSession(t.Context(), "u_1", "127.0.0.1", "", "")
+		is.NotError(t, err)
+
+		err = h.RevokeSession(t.Context(), "u_2", s.ID)
+		is.Error(t, model.ErrorSessionNotFound, err)
+
+		_, err = h.TouchSession(t.Context(), s.ID)
+		is.NotError(t, err)
+	})
+
+	internaltesting.Run(t, "revokes all sessions of a user", func(t *testing.T, h *sql.Helper) {
+		for range 2 {
+			_, err := h.CreateSession(t.Context(), "u_1", "127.0.0.1", "", "")
+			is.NotError(t, err)
+		}
+		other, err := h.CreateSession(t.Context(), "u_2", "127.0.0.1", "", "")
+		is.NotError(t, err)
+
+		err = h.RevokeSessions(t.Context(), "u_1")
+		is.NotError(t, err)
+
+		sessions, err := h.GetSessions(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.Equal(t, 0, len(sessions))
+
+		_, err = h.TouchSession(t.Context(), other.ID)
+		is.NotError(t, err)
+	})
+
+	internaltesting.Run(t, "deletes sessions not seen since a time", func(t *testing.T, h *sql.Helper) {
+		old, err := h.CreateSession(t.Context(), "u_1", "127.0.0.1", "", "")
+		is.NotError(t, err)
+		recent, err := h.CreateSession(t.Context(), "u_1", "127.0.0.1", "", "")
+		is.NotError(t, err)
+
+		err = h.Exec(t.Context(), `update user_sessions set last_seen = $1 where id = $2`, model.Time{T: time.Now().Add(-2 * time.Hour)}, old.ID)
+		is.NotError(t, err)
+
+		err = h.DeleteSessionsBefore(t.Context(), model.Time{T: time.Now().Add(-time.Hour)})
+		is.NotError(t, err)
+
+		sessions, err := h.GetSessions(t.Context(), "u_1")
+		is.NotError(t, err)
+		is.Equal(t, 1, len(sessions))
+		is.Equal(t, recent.ID, sessions[0].ID)
+	})
+}
+
+// failingUserDeactivator is a [sql.UserDeactivator] that always fails.
+type failingUserDeactivator struct{}
+
+func (failingUserDeactivator) DeactivateUser(ctx context.Context, tx *sql.Tx, userID model.UserID) error {
+	return errors.New("oh no")
+}
+
+func TestHelper_DeactivateUser(t *testing.T) {
+	internaltesting.Run(t, "deactivates the user and revokes all of their sessions", func(t *testing.T, h *sql.Helper) {
+		createTestUsers(t, h)
+
+		s, err := h.CreateSession(t.Context(), "u_1", "127.0.0.1", "", "")
+		is.NotError(t, err)
+		other, err := h.CreateSession(t.Context(), "u_2", "127.0.0.1", "", "")
+		is.NotError(t, err)
+
+		err = h.DeactivateUser(t.Context(), "u_1", testUsers{})
+		is.NotError(t, err)
+
+		var active bool
+		err = h.Get(t.Context(), &active, `select active from users where id = 'u_1'`)
+		is.NotError(t, err)
+		is.True(t, !active)
+
+		_, err = h.TouchSession(t.Context(), s.ID)
+		is.Error(t, model.ErrorSessionNotFound, err)
+
+		_, err = h.TouchSession(t.Context(), other.ID)
+		is.NotError(t, err)
+	})
+
+	internaltesting.Run(t, "keeps the sessions if deactivating fails", func(t *testing.T, h *sql.Helper) {
+		s, err := h.CreateSession(t.Context(), "u_1", "127.0.0.1", "", "")
+		is.NotError(t, err)
+
+		err = h.DeactivateUser(t.Context(), "u_1", failingUserDeactivator{})
+		is.True(t, err != nil)
+
+		_, err = h.TouchSession(t.Context(), s.ID)
+		is.NotError(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"maragu.dev/errors"
+
+	"maragu.dev/glue/model"
+)
+
+const (
+	// tokenKindLogin is for tokens that log a user in, sent in login and signup emails.
+	tokenKindLogin = "login"
+	// tokenKindEmailChange is for tokens that confirm an email address change, with the new address as data.
+	tokenKindEmailChange = "email-change"
+)
+
+// CreateLoginToken for the given user, valid for the given lifetime and usable once with [Helper.ConsumeLoginToken].
+// Only a hash of the token is stored, so the returned token can't be recovered from the database.
+// Expired tokens are deleted along the way.
+func (h *Helper) CreateLoginToken(ctx context.Context, userID model.UserID, lifetime time.Duration) (string, error) {
+	return h.createToken(ctx, tokenKindLogin, userID, "", lifetime)
+}
+
+// ConsumeLoginToken created with [Helper.CreateLoginToken], returning the user ID it was created for.
+// The token is deleted, so it can only be used once.
+// Returns [model.ErrorTokenNotFound] if the token doesn't exist or has been used, and [model.ErrorTokenExpired] if it has expired.
+func (h *Helper) ConsumeLoginToken(ctx context.Context, token string) (model.UserID, error) {
+	var userID model.UserID
+	err := h.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+		var err error
+		userID, _, err = consumeToken(ctx, tx, tokenKindLogin, token)
+		return err
+	})
+	return userID, err
+}
+
+// UserEmailUpdater is the part of the app's user store that [Helper.ConfirmEmailChange] uses within its transaction.
+type UserEmailUpdater interface {
+	// IsEmailTaken returns whether any user has the email address.
+	IsEmailTaken(ctx context.Context, tx *Tx, email model.EmailAddress) (bool, error)
+	// UpdateUserEmail to the given email address.
+	UpdateUserEmail(ctx context.Context, tx *Tx, userID model.UserID, email model.EmailAddress) error
+}
+
+// CreateEmailChangeToken for changing the given user's email address to email, valid for the given lifetime,
+// and usable once with [Helper.ConfirmEmailChange]. Like with [Helper.CreateLoginToken], only a hash of the token is stored.
+func (h *Helper) CreateEmailChangeToken(ctx context.Context, userID model.UserID, email model.EmailAddress, lifetime time.Duration) (string, error) {
+	return h.createToken(ctx, tokenKindEmailChange, userID, email.String(), lifetime)
+}
+
+// ConfirmEmailChange created with [Helper.CreateEmailChangeToken], updating the user's email address with u.
+// The token is consumed, the new email address checked for conflicts, and the user updated in one transaction,
+// so two users can't end up with the same address.
+// Returns [model.ErrorTokenNotFound] or [model.ErrorTokenExpired] like [Helper.ConsumeLoginToken],
+// and [model.ErrorEmailConflict] if another user has the new email address, in which case the token is not consumed.
+func (h *Helper) ConfirmEmailChange(ctx context.Context, token string, u UserEmailUpdater) (model.UserID, model.EmailAddress, error) {
+	var userID model.UserID
+	var email model.EmailAddress
+	err := h.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+		var data string
+		var err error
+		userID, data, err = consumeToken(ctx, tx, tokenKindEmailChange, token)
+		if err != nil {
+			return err
+		}
+		email = model.EmailAddress(data)
+
+		taken, err := u.IsEmailTaken(ctx, tx, email)
+		if err != nil {
+			return errors.Wrap(err, "error checking if email is taken")
+		}
+		if taken {
+			return model.ErrorEmailConflict
+		}
+
+		if err := u.UpdateUserEmail(ctx, tx, userID, email); err != nil {
+			return errors.Wrap(err, "error updating user email")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return userID, email, nil
+}
+
+func (h *Helper) createToken(ctx context.Context, kind string, userID model.UserID, data string, lifetime time.Duration) (string, error) {
+	token := rand.Text()
+
+	err := h.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+		now := model.Now()
+
+		if err := tx.Exec(ctx, `delete from tokens where expires < $1`, now); err != nil {
+			return errors.Wrap(err, "error deleting expired tokens")
+		}
+
+		query := `insert into tokens (hash, kind, user_id, data, expires, created) values ($1, $2, $3, $4, $5, $6)`
+		expires := model.Time{T: now.T.Add(lifetime)}
+		if err := tx.Exec(ctx, query, hashToken(token), kind, userID, data, expires, now); err != nil {
+			return errors.Wrap(err, "error inserting token")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeToken of the given kind by deleting it, returning the user ID and data it was created with.
+func consumeToken(ctx context.Context, tx *Tx, kind, token string) (model.UserID, string, error) {
+	var t struct {
+		UserID  model.UserID `db:"user_id"`
+		Data    string
+		Expires model.Time
+	}
+	query := `delete from tokens where hash = $1 and kind = $2 returning user_id, data, expires`
+	if err := tx.Get(ctx, &t, query, hashToken(token), kind); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", model.ErrorTokenNotFound
+		}
+		return "", "", errors.Wrap(err, "error deleting token")
+	}
+
+	if t.Expires.T.Before(time.Now()) {
+		return "", "", model.ErrorTokenExpired
+	}
+
+	return t.UserID, t.Data, nil
+}
+
+// hashToken for storage, so a database leak doesn't leak usable tokens.
+// Tokens are random and long, so a fast unsalted hash is enough.
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
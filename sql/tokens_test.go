@@ -0,0 +1,132 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/glue/model"
+	"maragu.dev/glue/sql"
+	internaltesting "maragu.dev/glue/sql/internal/testing"
+)
+
+func TestHelper_LoginTokens(t *testing.T) {
+	internaltesting.Run(t, "creates a token that can be consumed once", func(t *testing.T, h *sql.Helper) {
+		token, err := h.CreateLoginToken(t.Context(), "u_123", time.Minute)
+		is.NotError(t, err)
+
+		userID, err := h.ConsumeLoginToken(t.Context(), token)
+		is.NotError(t, err)
+		is.Equal(t, model.UserID("u_123"), userID)
+
+		_, err = h.ConsumeLoginToken(t.Context(), token)
+		is.Error(t, model.ErrorTokenNotFound, err)
+	})
+
+	internaltesting.Run(t, "does not store the token in plain text", func(t *testing.T, h *sql.Helper) {
+		token, err := h.CreateLoginToken(t.Context(), "u_123", time.Minute)
+		is.NotError(t, err)
+
+		var count int
+		err = h.Get(t.Context(), &count, `select count(*) from tokens where hash = $1`, token)
+		is.NotError(t, err)
+		is.Equal(t, 0, count)
+	})
+
+	internaltesting.Run(t, "errors if the token has expired", func(t *testing.T, h *sql.Helper) {
+		token, err := h.CreateLoginToken(t.Context(), "u_123", -time.Minute)
+		is.NotError(t, err)
+
+		_, err = h.ConsumeLoginToken(t.Context(), token)
+		is.Error(t, model.ErrorTokenExpired, err)
+	})
+
+	internaltesting.Run(t, "errors if the token does not exist", func(t *testing.T, h *sql.Helper) {
+		_, err := h.ConsumeLoginToken(t.Context(), "doesnotexist")
+		is.Error(t, model.ErrorTokenNotFound, err)
+	})
+}
+
+// testUsers is a [sql.UserEmailUpdater] and [sql.UserDeactivator] for a users table created in the test.
+type testUsers struct{}
+
+func (testUsers) IsEmailTaken(ctx context.Context, tx *sql.Tx, email model.EmailAddress) (bool, error) {
+	var count int
+	err := tx.Get(ctx, &count, `select count(*) from users where email = $1`, email)
+	return count > 0, err
+}
+
+func (testUsers) UpdateUserEmail(ctx context.Context, tx *sql.Tx, userID model.UserID, email model.EmailAddress) error {
+	return tx.Exec(ctx, `update users set email = $1 where id = $2`, email, userID)
+}
+
+func (testUsers) DeactivateUser(ctx context.Context, tx *sql.Tx, userID model.UserID) error {
+	return tx.Exec(ctx, `update users set active = false where id = $1`, userID)
+}
+
+func TestHelper_ConfirmEmailChange(t *testing.T) {
+	internaltesting.Run(t, "changes the email address of the user", func(t *testing.T, h *sql.Helper) {
+		createTestUsers(t, h)
+
+		token, err := h.CreateEmailChangeToken(t.Context(), "u_1", "new@example.com", time.Minute)
+		is.NotError(t, err)
+
+		userID, email, err := h.ConfirmEmailChange(t.Context(), token, testUsers{})
+		is.NotError(t, err)
+		is.Equal(t, model.UserID("u_1"), userID)
+		is.Equal(t, model.EmailAddress("new@example.com"), email)
+
+		var newEmail model.EmailAddress
+		err = h.Get(t.Context(), &newEmail, `select email from users where id = 'u_1'`)
+		is.NotError(t, err)
+		is.Equal(t, model.EmailAddress("new@example.com"), newEmail)
+
+		_, _, err = h.ConfirmEmailChange(t.Context(), token, testUsers{})
+		is.Error(t, model.ErrorTokenNotFound, err)
+	})
+
+	internaltesting.Run(t, "errors on conflict with another user and keeps the token", func(t *testing.T, h *sql.Helper) {
+		createTestUsers(t, h)
+
+		token, err := h.CreateEmailChangeToken(t.Context(), "u_1", "two@example.com", time.Minute)
+		is.NotError(t, err)
+
+		_, _, err = h.ConfirmEmailChange(t.Context(), token, testUsers{})
+		is.Error(t, model.ErrorEmailConflict, err)
+
+		var email model.EmailAddress
+		err = h.Get(t.Context(), &email, `select email from users where id = 'u_1'`)
+		is.NotError(t, err)
+		is.Equal(t, model.EmailAddress("one@example.com"), email)
+
+		var count int
+		err = h.Get(t.Context(), &count, `select count(*) from tokens`)
+		is.NotError(t, err)
+		is.Equal(t, 1, count)
+	})
+
+	internaltesting.Run(t, "does not accept a login token", func(t *testing.T, h *sql.Helper) {
+		createTestUsers(t, h)
+
+		token, err := h.CreateLoginToken(t.Context(), "u_1", time.Minute)
+		is.NotError(t, err)
+
+		_, _, err = h.ConfirmEmailChange(t.Context(), token, testUsers{})
+		is.Error(t, model.ErrorTokenNotFound, err)
+	})
+}
+
+func createTestUsers(t *testing.T, h *sql.Helper) {
+	t.Helper()
+
+	err := h.Exec(t.Context(), `create table users (id text primary key, email text not null unique, active boolean not null default true)`)
+	is.NotError(t, err)
+	t.Cleanup(func() {
+		_ = h.Exec(context.WithoutCancel(t.Context()), `drop table users`)
+	})
+
+	err = h.Exec(t.Context(), `insert into users (id, email) values ('u_1', 'one@example.com'), ('u_2', 'two@example.com')`)
+	is.NotError(t, err)
+}
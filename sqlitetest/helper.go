@@ -42,6 +42,11 @@ func NewHelper(t *testing.T, opts ...NewHelperOption) *sql.Helper {
 	if err := h.Connect(t.Context()); err != nil {
 		t.Fatal(err)
 	}
+	t.Cleanup(func() {
+		if err := h.Close(); err != nil {
+			t.Error(err)
+		}
+	})
 
 	if config.migrationFunc == nil {
 		config.migrationFunc = func(ctx context.Context, _ *stdsql.DB) error {